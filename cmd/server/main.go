@@ -1,18 +1,61 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/prakhar-5447/GoDB/internal/audit"
 	"github.com/prakhar-5447/GoDB/internal/auth"
+	"github.com/prakhar-5447/GoDB/internal/config"
 	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/health"
+	"github.com/prakhar-5447/GoDB/internal/metrics"
+	"github.com/prakhar-5447/GoDB/internal/prepared"
+	"github.com/prakhar-5447/GoDB/internal/query"
 	"github.com/prakhar-5447/GoDB/internal/service"
+	"github.com/prakhar-5447/GoDB/internal/slowlog"
+	"github.com/prakhar-5447/GoDB/internal/tracing"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
+	verifyAudit := flag.Bool("verify-audit-log", false, "verify the audit log's hash chain and exit")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration and exit")
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := config.Load(configFlags)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+	if *printConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		return
+	}
+	db.DBDir = cfg.DataDir
+	auth.DBPath = cfg.AuthDB
+	configureAudit(cfg.Logging.Audit)
+	if *verifyAudit {
+		os.Exit(verifyAuditLog())
+	}
+
+	// Keep credentials and sensitive values out of the server log as well.
+	log.SetOutput(audit.RedactingWriter(os.Stderr))
 	audit.InitAuditLogger()
 
 	// Ensure the authentication database is initialized.
@@ -25,17 +68,185 @@ func main() {
 		log.Fatalf("failed to create database directory: %v", err)
 	}
 
+	// Tell new users where to connect.
+	service.AdvertiseAddr = cfg.AdvertiseAddr
+
+	// Cap the rows a single query may return.
+	query.MaxRows = cfg.Limits.MaxRows
+
+	configureSlowLog(cfg.Logging.SlowQuery)
+
 	// Start gRPC server
-	listener, err := net.Listen("tcp", ":50051")
+	listener, err := net.Listen("tcp", cfg.ListenAddr)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
 
-	grpcServer := grpc.NewServer()
+	configureTracing(cfg.Features.Tracing)
+
+	// Shut down gracefully on SIGINT or SIGTERM.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Every RPC is traced, counted and recorded in the audit log.
+	options := []grpc.ServerOption{grpc.ChainUnaryInterceptor(
+		tracing.UnaryServerInterceptor,
+		metrics.UnaryServerInterceptor,
+		audit.UnaryServerInterceptor,
+	)}
+	if cfg.TLS.Enabled() {
+		tlsConfig, err := cfg.TLS.ServerConfig()
+		if err != nil {
+			log.Fatalf("Failed to configure TLS: %v", err)
+		}
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	grpcServer := grpc.NewServer(options...)
 	service.RegisterGRPCServices(grpcServer)
 
-	log.Println("🚀 gRPC server running on port 50051...")
-	if err := grpcServer.Serve(listener); err != nil {
+	// Report serving status through grpc.health.v1 while the auth database
+	// and data directory stay usable.
+	healthChecker := health.Register(grpcServer,
+		health.Probe{Name: "auth database", Check: auth.CheckAuthDatabase},
+		health.Probe{Name: "data directory", Check: db.CheckDBDirectory},
+	)
+	healthChecker.Start(ctx)
+
+	// Let tools such as grpcurl list and describe the services.
+	if cfg.Features.Reflection {
+		reflection.Register(grpcServer)
+		log.Println("gRPC server reflection enabled")
+	}
+
+	if cfg.Features.Metrics.Enabled {
+		go serveMetrics(cfg.Features.Metrics.Addr)
+	}
+
+	// Suggest (and, if the admin policy allows, create) indexes in the background.
+	advisorDone := service.StartIndexAdvisor(ctx)
+
+	log.Printf("🚀 gRPC server running on %s (TLS: %t)...", cfg.ListenAddr, cfg.TLS.Enabled())
+	served := make(chan error, 1)
+	go func() { served <- grpcServer.Serve(listener) }()
+	select {
+	case err := <-served:
 		log.Fatalf("Failed to start gRPC server: %v", err)
+	case <-ctx.Done():
+	}
+	// A second signal kills the server without waiting.
+	stop()
+	shutdown(grpcServer, healthChecker, advisorDone, time.Duration(cfg.Limits.ShutdownTimeout))
+}
+
+// shutdown drains in-flight RPCs for up to timeout, cancelling those still
+// running afterwards, waits for the index advisor to stop, then releases the
+// databases and flushes the logs.
+func shutdown(grpcServer *grpc.Server, healthChecker *health.Checker, advisorDone <-chan struct{}, timeout time.Duration) {
+	log.Printf("Shutting down; draining RPCs for up to %s", timeout)
+	healthChecker.Shutdown()
+	drained := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(timeout):
+		log.Println("RPCs still running at the shutdown deadline; cancelling them")
+		grpcServer.Stop()
+	}
+	// The advisor was stopped by the signal; a run in progress ends after
+	// the database it is checking.
+	<-advisorDone
+
+	prepared.CloseAll()
+	if err := db.Shutdown(); err != nil {
+		log.Printf("Database shutdown: %v", err)
+	}
+	if err := slowlog.Close(); err != nil {
+		log.Printf("Failed to close slow query log: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := tracing.Shutdown(ctx); err != nil {
+		log.Printf("Failed to flush traces: %v", err)
+	}
+	audit.LogEvent("Server stopped")
+	if err := audit.Close(); err != nil {
+		log.Printf("Failed to close audit log: %v", err)
+	}
+	log.Println("Server stopped")
+}
+
+// configureTracing installs the span exporter: "file" appends spans to a
+// local file, "otlp" posts them to an OTLP/HTTP collector.
+func configureTracing(t config.Tracing) {
+	switch t.Exporter {
+	case "file":
+		fileExporter, err := tracing.NewFileExporter(t.File)
+		if err != nil {
+			log.Fatalf("Failed to open trace file: %v", err)
+		}
+		tracing.SetExporter(fileExporter)
+		log.Printf("Writing traces to %s", t.File)
+	case "otlp":
+		tracing.SetExporter(tracing.NewOTLPExporter(t.OTLPEndpoint))
+		log.Printf("Exporting traces to %s", t.OTLPEndpoint)
+	}
+}
+
+// configureSlowLog opens the slow query log. The thresholds were checked
+// when the configuration was loaded.
+func configureSlowLog(c config.SlowQuery) {
+	thresholds, err := slowlog.ParseThresholds(c.Threshold)
+	if err != nil {
+		log.Fatalf("Invalid slow query threshold: %v", err)
+	}
+	if _, ok := thresholds[""]; !ok {
+		thresholds[""] = slowlog.Thresholds[""]
+	}
+	slowlog.Thresholds = thresholds
+	if err := slowlog.Open(c.Log); err != nil {
+		log.Fatalf("Failed to open slow query log: %v", err)
+	}
+}
+
+// serveMetrics serves /metrics on addr.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Default.Handler())
+	log.Printf("📈 Metrics available on %s/metrics", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("Failed to serve metrics: %v", err)
+	}
+}
+
+// configureAudit applies the audit log location, rotation and redaction
+// settings.
+func configureAudit(c config.Audit) {
+	audit.SetSensitiveColumns(c.SensitiveColumns)
+	audit.Dir = c.Dir
+	audit.KeyFile = c.KeyFile
+	audit.PublicKeyFile = c.PublicKeyFile
+	audit.MaxSegmentSize = c.MaxSize
+	audit.MaxSegmentAge = time.Duration(c.MaxAge)
+	audit.Retention = time.Duration(c.Retention)
+	audit.Compress = c.Compress
+}
+
+// verifyAuditLog checks the audit log and returns the process exit code:
+// 0 when the chain is intact, 1 when it is broken and 2 on errors.
+func verifyAuditLog() int {
+	v, err := audit.VerifyAuditLog()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "audit log verification failed: %v\n", err)
+		return 2
+	}
+	if !v.Valid {
+		fmt.Printf("audit log broken in %s at line %d (seq %d): %s\n", v.BrokenSegment, v.BrokenLine, v.BrokenSeq, v.Reason)
+		return 1
 	}
+	fmt.Printf("audit log intact: %d records (seq %d-%d), %d checkpoints, %d unchained lines, %d unsigned records after the last checkpoint\n",
+		v.Records, v.FirstSeq, v.LastSeq, v.Checkpoints, v.Unchained, v.Unsigned)
+	return 0
 }
@@ -0,0 +1,213 @@
+// Package advisor suggests indexes from the access patterns recorded in
+// internal/stats and the tables that recorded plans scan without an index.
+package advisor
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prakhar-5447/GoDB/internal/auth"
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/stats"
+)
+
+// policySettingKey is where the admin-set policy is stored in the auth database.
+const policySettingKey = "index_advisor_policy"
+
+// Policy controls which recommendations are reported and whether the
+// background advisor creates them automatically.
+type Policy struct {
+	// AutoApply creates recommended indexes from the background loop.
+	AutoApply bool `json:"auto_apply"`
+	// MinOccurrences is the minimum number of times a pattern must have been seen.
+	MinOccurrences int64 `json:"min_occurrences"`
+	// MinBenefit is the minimum estimated benefit (rows not read) to report.
+	MinBenefit float64 `json:"min_benefit"`
+	// LargeTableRows is the row count from which a scanned table is considered large.
+	LargeTableRows int64 `json:"large_table_rows"`
+	// MaxIndexesPerTable stops recommendations for tables that already have this many indexes.
+	MaxIndexesPerTable int `json:"max_indexes_per_table"`
+	// Interval is how often the background advisor runs.
+	Interval time.Duration `json:"interval"`
+}
+
+// DefaultPolicy is used until an admin stores a policy.
+var DefaultPolicy = Policy{
+	AutoApply:          false,
+	MinOccurrences:     10,
+	MinBenefit:         10000,
+	LargeTableRows:     10000,
+	MaxIndexesPerTable: 8,
+	Interval:           10 * time.Minute,
+}
+
+// LoadPolicy reads the admin-set policy, falling back to DefaultPolicy.
+func LoadPolicy() (Policy, error) {
+	value, ok, err := auth.GetSetting(policySettingKey)
+	if err != nil || !ok {
+		return DefaultPolicy, err
+	}
+	policy := DefaultPolicy
+	if err := json.Unmarshal([]byte(value), &policy); err != nil {
+		return DefaultPolicy, fmt.Errorf("invalid stored advisor policy: %w", err)
+	}
+	return policy, nil
+}
+
+// SavePolicy validates and stores the advisor policy.
+func SavePolicy(policy Policy) error {
+	if policy.MinOccurrences < 0 || policy.MinBenefit < 0 || policy.LargeTableRows < 0 || policy.MaxIndexesPerTable < 0 {
+		return fmt.Errorf("advisor policy thresholds must not be negative")
+	}
+	if policy.Interval < time.Minute {
+		return fmt.Errorf("advisor interval must be at least one minute")
+	}
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	return auth.SetSetting(policySettingKey, string(value))
+}
+
+// Recommendation is a suggested index.
+type Recommendation struct {
+	Table   string
+	Columns []string
+	// Benefit estimates how many row reads per recorded workload the index saves.
+	Benefit     float64
+	Occurrences int64
+	Reason      string
+}
+
+// IndexName derives a name for the recommended index.
+func (r Recommendation) IndexName() string {
+	return fmt.Sprintf("%s_%s_idx", r.Table, strings.Join(r.Columns, "_"))
+}
+
+// Recommend computes index recommendations for the database identified by
+// key from its recorded workload. Only tables that recorded plans have
+// scanned in full and that hold at least policy.LargeTableRows rows qualify.
+func Recommend(database *sql.DB, key string, policy Policy) ([]Recommendation, error) {
+	scanned := map[string]bool{}
+	for _, scan := range stats.IndexUsage(key).Scans {
+		scanned[strings.ToLower(scan.Table)] = true
+	}
+
+	candidates := map[string]*Recommendation{}
+	rowCounts := map[string]int64{}
+	existing := map[string][][]string{}
+
+	for _, pattern := range stats.Workload(key) {
+		table := pattern.Table
+		if !scanned[strings.ToLower(table)] {
+			continue
+		}
+		columns := candidateColumns(pattern)
+		if len(columns) == 0 {
+			continue
+		}
+
+		if _, ok := rowCounts[table]; !ok {
+			exists, err := db.TableExists(database, table)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				rowCounts[table] = -1
+				continue
+			}
+			count, err := db.RowCount(database, key, table)
+			if err != nil {
+				return nil, err
+			}
+			rowCounts[table] = count
+
+			indexes, err := db.ListIndexes(database, table)
+			if err != nil {
+				return nil, err
+			}
+			for _, idx := range indexes {
+				existing[table] = append(existing[table], idx.Columns)
+			}
+		}
+		rows := rowCounts[table]
+		if rows < policy.LargeTableRows || rows <= 0 {
+			continue
+		}
+		if policy.MaxIndexesPerTable > 0 && len(existing[table]) >= policy.MaxIndexesPerTable {
+			continue
+		}
+		if coveredByIndex(columns, existing[table]) {
+			continue
+		}
+
+		distinct, err := db.DistinctCount(database, key, table, columns[0])
+		if err != nil {
+			return nil, err
+		}
+		// Rows the index lets each statement skip, assuming uniform values.
+		saved := float64(rows) - float64(rows)/float64(distinct)
+
+		sig := strings.ToLower(table + "|" + strings.Join(columns, ","))
+		rec, ok := candidates[sig]
+		if !ok {
+			rec = &Recommendation{Table: table, Columns: columns}
+			candidates[sig] = rec
+		}
+		rec.Occurrences += pattern.Count
+		rec.Benefit += saved * float64(pattern.Count)
+	}
+
+	var recommendations []Recommendation
+	for _, rec := range candidates {
+		if rec.Occurrences < policy.MinOccurrences || rec.Benefit < policy.MinBenefit {
+			continue
+		}
+		rec.Reason = fmt.Sprintf("%d statements scanned all %d rows of %s filtering or sorting on %s",
+			rec.Occurrences, rowCounts[rec.Table], rec.Table, strings.Join(rec.Columns, ", "))
+		recommendations = append(recommendations, *rec)
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		if recommendations[i].Benefit != recommendations[j].Benefit {
+			return recommendations[i].Benefit > recommendations[j].Benefit
+		}
+		return recommendations[i].IndexName() < recommendations[j].IndexName()
+	})
+	return recommendations, nil
+}
+
+// candidateColumns orders index keys the way SQLite can use them: equality
+// columns first, then at most one range column, falling back to sort keys.
+func candidateColumns(p stats.AccessPattern) []string {
+	columns := append([]string(nil), p.Equality...)
+	if len(p.Range) > 0 {
+		columns = append(columns, p.Range[0])
+	} else if len(columns) == 0 {
+		columns = append(columns, p.Sort...)
+	}
+	return columns
+}
+
+// coveredByIndex reports whether an existing index starts with the given columns.
+func coveredByIndex(columns []string, indexes [][]string) bool {
+	for _, idx := range indexes {
+		if len(idx) < len(columns) {
+			continue
+		}
+		match := true
+		for i, col := range columns {
+			if !strings.EqualFold(idx[i], col) {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
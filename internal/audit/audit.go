@@ -1,28 +1,164 @@
 package audit
 
 import (
+	"bytes"
+	"encoding/json"
 	"log"
 	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
 )
 
 var AuditLogger *log.Logger
 
-// InitAuditLogger initializes the audit logger.
+// KeyFile holds the Ed25519 seed signing checkpoints and PublicKeyFile its
+// public key, which is all verification needs. Keep KeyFile outside Dir, out
+// of reach of anyone who can edit the log.
+var (
+	KeyFile       = filepath.Join(db.DBDir, "audit.key")
+	PublicKeyFile = KeyFile + ".pub"
+)
+
+// out is the current segment AuditLogger writes to.
+var out *segmentWriter
+
+// Outcomes recorded on RPC events.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event is one audit record, written to the audit log as a single JSON line.
+// RPC events are emitted by UnaryServerInterceptor; handlers add free-text
+// events through LogEvent, which only set Message.
+type Event struct {
+	Time time.Time `json:"time"`
+	// Seq numbers the records of the hash chain; see chain.go.
+	Seq uint64 `json:"seq,omitempty"`
+	// Kind is empty for ordinary events and KindCheckpoint for checkpoints.
+	Kind      string `json:"kind,omitempty"`
+	Principal string `json:"principal,omitempty"`
+	// Claimed marks a Principal taken from the request whose credentials
+	// were not verified, e.g. a failed login.
+	Claimed    bool   `json:"claimed,omitempty"`
+	ClientAddr string `json:"client_addr,omitempty"`
+	// Action is the RPC name (e.g. "QueryData"), Method its full gRPC method.
+	Action   string `json:"action,omitempty"`
+	Method   string `json:"method,omitempty"`
+	Database string `json:"database,omitempty"`
+	Table    string `json:"table,omitempty"`
+	// RowsAffected is set for RPCs whose response reports it.
+	RowsAffected *int64  `json:"rows_affected,omitempty"`
+	Outcome      string  `json:"outcome,omitempty"`
+	Code         string  `json:"code,omitempty"`
+	LatencyMS    float64 `json:"latency_ms,omitempty"`
+	Error        string  `json:"error,omitempty"`
+	Message      string  `json:"message,omitempty"`
+	// PrevHash is the hash of the previous record; Signature signs it on
+	// checkpoint records.
+	PrevHash  string `json:"prev_hash,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// InitAuditLogger opens the audit log in Dir, applies retention and resumes
+// the hash chain of existing segments.
 func InitAuditLogger() {
-	// Open (or create) an audit log file in append mode.
-	file, err := os.OpenFile("audit.log", os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	// The log and its key are readable by the server's user only.
+	if err := os.MkdirAll(Dir, 0700); err != nil {
+		log.Fatalf("Failed to create audit log directory: %v", err)
+	}
+	key, err := signingKey()
+	if err != nil {
+		log.Fatalf("Failed to load audit signing key: %v", err)
+	}
+	if err := prune(Dir, time.Now()); err != nil {
+		log.Fatalf("Failed to apply audit log retention: %v", err)
+	}
+	list, err := segments(Dir)
 	if err != nil {
+		log.Fatalf("Failed to read audit log: %v", err)
+	}
+	last, started, err := resume(list)
+	if err != nil {
+		log.Fatalf("Failed to read audit log: %v", err)
+	}
+	if out, err = openSegmentWriter(Dir, started); err != nil {
 		log.Fatalf("Failed to open audit log file: %v", err)
 	}
-	// Create a logger with a specific prefix and log format.
-	AuditLogger = log.New(file, "AUDIT: ", log.Ldate|log.Ltime|log.Lshortfile)
+	// Events carry their own timestamp; each line is a bare JSON object.
+	AuditLogger = log.New(out, "", 0)
+
+	mu.Lock()
+	defer mu.Unlock()
+	state = chain{link: last, key: key}
+	state.checkpoint()
 }
 
-// LogEvent logs an audit event.
-func LogEvent(event string) {
-	if AuditLogger != nil {
-		AuditLogger.Println(event)
-	} else {
-		log.Println("AUDIT: ", event)
+// Record writes an audit event. A zero Time is set to the current time, and
+// the message and error are redacted.
+func Record(e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	e.Time = e.Time.UTC()
+	e.Message = Redact(e.Message)
+	e.Error = Redact(e.Error)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if AuditLogger == nil {
+		line, err := encodeEvent(e)
+		if err != nil {
+			log.Printf("AUDIT: failed to encode event: %v", err)
+			return
+		}
+		log.Print("AUDIT: ", string(line))
+		return
 	}
+	if out.due(e.Time) {
+		// Every segment starts with a checkpoint so that it can be verified
+		// once older segments are gone.
+		if err := out.rotate(e.Time); err != nil {
+			log.Printf("AUDIT: failed to rotate audit log: %v", err)
+		} else {
+			state.checkpoint()
+		}
+	}
+	state.append(e)
+	if CheckpointInterval > 0 && state.sinceCheckpoint >= CheckpointInterval {
+		state.checkpoint()
+	}
+}
+
+// Close signs the head of the chain with a final checkpoint, waits for
+// rotated segments to finish compressing and closes the log. Events recorded
+// afterwards go to the server log, as before InitAuditLogger.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if AuditLogger == nil {
+		return nil
+	}
+	state.checkpoint()
+	err := out.Close()
+	AuditLogger, out = nil, nil
+	return err
+}
+
+// encodeEvent renders e as a single-line JSON object without a newline.
+func encodeEvent(e Event) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(e); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// LogEvent logs a free-text audit event.
+func LogEvent(event string) {
+	Record(Event{Message: event})
 }
@@ -0,0 +1,355 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The audit log is a hash chain: every record carries the hash of the record
+// before it in prev_hash and ends with its own hash, the SHA-256 of the line
+// up to that field. Editing, inserting or removing a record breaks the link
+// to the next one. Checkpoint records additionally sign the hash they follow
+// with the server's Ed25519 key, so that the chain cannot simply be
+// recomputed without the key.
+
+// KindCheckpoint marks signed checkpoint records.
+const KindCheckpoint = "checkpoint"
+
+// CheckpointInterval is the number of records between signed checkpoints.
+// A checkpoint is also written whenever the logger starts.
+var CheckpointInterval = 1000
+
+const (
+	hashField = `,"hash":"`
+	// hashSuffixLen is the length of `,"hash":"<64 hex digits>"}`.
+	hashSuffixLen = len(hashField) + sha256.Size*2 + len(`"}`)
+)
+
+var (
+	mu    sync.Mutex
+	state chain
+)
+
+// link identifies the last record of a chain.
+type link struct {
+	seq  uint64
+	hash string
+}
+
+type chain struct {
+	link
+	key             ed25519.PrivateKey
+	sinceCheckpoint int
+}
+
+// append writes e as the next record of the chain.
+func (c *chain) append(e Event) {
+	e.Seq = c.seq + 1
+	e.PrevHash = c.hash
+	body, err := encodeEvent(e)
+	if err != nil {
+		log.Printf("AUDIT: failed to encode event: %v", err)
+		return
+	}
+	hash := hashRecord(body)
+	AuditLogger.Print(string(body[:len(body)-1]) + hashField + hash + `"}`)
+	c.link = link{seq: e.Seq, hash: hash}
+	c.sinceCheckpoint++
+}
+
+// checkpoint writes a record signing the current head of the chain.
+func (c *chain) checkpoint() {
+	c.append(Event{
+		Time:      time.Now().UTC(),
+		Kind:      KindCheckpoint,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(c.key, []byte(c.hash))),
+	})
+	c.sinceCheckpoint = 0
+}
+
+func hashRecord(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// splitRecord separates a chained line into the hashed body and its hash.
+// ok is false for lines that do not end with a hash field.
+func splitRecord(line string) (body, hash string, ok bool) {
+	if len(line) < hashSuffixLen || !strings.HasSuffix(line, `"}`) {
+		return "", "", false
+	}
+	cut := len(line) - hashSuffixLen
+	if !strings.HasPrefix(line[cut:], hashField) {
+		return "", "", false
+	}
+	hash = line[cut+len(hashField) : len(line)-2]
+	if _, err := hex.DecodeString(hash); err != nil {
+		return "", "", false
+	}
+	return line[:cut] + "}", hash, true
+}
+
+// resume finds the head of the chain, searching the segments from newest to
+// oldest, and the time of the first record in the current segment.
+func resume(list []segment) (last link, started time.Time, err error) {
+	for i := len(list) - 1; i >= 0; i-- {
+		seg := list[i]
+		r, err := seg.open()
+		if err != nil {
+			return link{}, time.Time{}, err
+		}
+		found := false
+		err = readLines(r, func(_ int64, line string) bool {
+			body, hash, ok := splitRecord(line)
+			var e Event
+			if ok && json.Unmarshal([]byte(body), &e) == nil {
+				last, found = link{seq: e.Seq, hash: hash}, true
+				if seg.closed.IsZero() && started.IsZero() {
+					started = e.Time
+				}
+			}
+			return true
+		})
+		r.Close()
+		if err != nil || found {
+			return last, started, err
+		}
+	}
+	return last, started, nil
+}
+
+// readLines calls fn with every line of r and its 1-based number until fn
+// returns false. Lines may be longer than bufio.Scanner allows.
+func readLines(r io.Reader, fn func(n int64, line string) bool) error {
+	reader := bufio.NewReader(r)
+	for n := int64(1); ; n++ {
+		line, err := reader.ReadString('\n')
+		if line != "" && !fn(n, strings.TrimRight(line, "\r\n")) {
+			return nil
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// legacyKeyFile is where the signing key was kept before KeyFile, in Dir.
+const legacyKeyFile = "audit.key"
+
+// signingKey loads KeyFile, generating it on first start, and saves its
+// public key to PublicKeyFile unless that already holds a different key. A
+// key left in Dir by earlier versions is moved to KeyFile, so that existing
+// checkpoints still verify.
+func signingKey() (ed25519.PrivateKey, error) {
+	if err := os.MkdirAll(filepath.Dir(KeyFile), 0700); err != nil {
+		return nil, err
+	}
+	legacy := filepath.Join(Dir, legacyKeyFile)
+	if _, err := os.Stat(KeyFile); errors.Is(err, os.ErrNotExist) {
+		if _, err := os.Stat(legacy); err == nil {
+			if err := os.Rename(legacy, KeyFile); err != nil {
+				return nil, fmt.Errorf("failed to move %s to %s: %w", legacy, KeyFile, err)
+			}
+			log.Printf("Moved the audit signing key out of the log directory to %s", KeyFile)
+		}
+	}
+	key, err := loadKey(KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pub := key.Public().(ed25519.PublicKey)
+	saved, err := loadPublicKey(PublicKeyFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := os.MkdirAll(filepath.Dir(PublicKeyFile), 0700); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(PublicKeyFile, []byte(hex.EncodeToString(pub)+"\n"), 0644); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case !saved.Equal(pub):
+		// Replacing it would let a new key vouch for a rewritten log.
+		return nil, fmt.Errorf("%s holds a different key than %s; remove it if the signing key was replaced on purpose", PublicKeyFile, KeyFile)
+	}
+	return key, nil
+}
+
+// loadKey reads the hex-encoded Ed25519 seed at path, generating and saving
+// a new one when the file does not exist.
+func loadKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		seed := make([]byte, ed25519.SeedSize)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(hex.EncodeToString(seed)+"\n"), 0600); err != nil {
+			return nil, err
+		}
+		return ed25519.NewKeyFromSeed(seed), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	seed, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%s is not a valid audit signing key", path)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// loadPublicKey reads the hex-encoded Ed25519 public key at path.
+func loadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%s is not a valid audit public key", path)
+	}
+	return ed25519.PublicKey(pub), nil
+}
+
+// Verification is the outcome of walking an audit log's hash chain.
+type Verification struct {
+	Valid bool
+	// Records and Checkpoints count the chained records that verified, up
+	// to and including the last checkpoint; LastSeq is that checkpoint's.
+	Records     int64
+	Checkpoints int64
+	FirstSeq    uint64
+	LastSeq     uint64
+	// Unsigned counts the records after the last checkpoint. Their hashes
+	// link up, but without a signature they could have been edited and
+	// re-hashed, so they are not counted as verified.
+	Unsigned int64
+	// Unchained counts the lines written before the log was chained. They
+	// are only accepted ahead of the first chained record.
+	Unchained int64
+	// BrokenSegment, BrokenLine and BrokenSeq locate the first record that
+	// failed, and Reason says why.
+	BrokenSegment string
+	BrokenLine    int64
+	BrokenSeq     uint64
+	Reason        string
+}
+
+// VerifyAuditLog checks every segment of the server's audit log, oldest
+// first, against the public key in PublicKeyFile; the signing key is not
+// needed. The first chained record is taken as the start of the chain, since
+// older segments may have been removed by retention; it must be a
+// checkpoint, as every segment starts with one.
+func VerifyAuditLog() (*Verification, error) {
+	pub, err := loadPublicKey(PublicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	return verifyLog(Dir, pub)
+}
+
+// verifyLog checks the segments in dir against pub.
+func verifyLog(dir string, pub ed25519.PublicKey) (*Verification, error) {
+	list, err := segments(dir)
+	if err != nil {
+		return nil, err
+	}
+	v := &verifier{Verification: Verification{Valid: true}, pub: pub}
+	for _, seg := range list {
+		r, err := seg.open()
+		if err != nil {
+			return nil, err
+		}
+		err = v.verify(filepath.Base(seg.path), r)
+		r.Close()
+		if err != nil {
+			return nil, err
+		}
+		if !v.Valid {
+			break
+		}
+	}
+	return &v.Verification, nil
+}
+
+type verifier struct {
+	Verification
+	pub  ed25519.PublicKey
+	prev *link
+}
+
+// verify walks the records of one segment, stopping at the first broken link.
+func (v *verifier) verify(name string, r io.Reader) error {
+	fail := func(n int64, seq uint64, format string, args ...interface{}) bool {
+		v.Valid = false
+		v.BrokenSegment, v.BrokenLine, v.BrokenSeq = name, n, seq
+		v.Reason = fmt.Sprintf(format, args...)
+		return false
+	}
+
+	return readLines(r, func(n int64, line string) bool {
+		body, hash, ok := splitRecord(line)
+		if !ok {
+			if v.prev == nil {
+				v.Unchained++
+				return true
+			}
+			return fail(n, 0, "record has no hash")
+		}
+		var e Event
+		if err := json.Unmarshal([]byte(body), &e); err != nil {
+			return fail(n, 0, "record is not valid JSON: %v", err)
+		}
+		if hashRecord([]byte(body)) != hash {
+			return fail(n, e.Seq, "record does not match its hash")
+		}
+		if v.prev != nil {
+			if e.Seq != v.prev.seq+1 {
+				return fail(n, e.Seq, "expected sequence number %d, found %d", v.prev.seq+1, e.Seq)
+			}
+			if e.PrevHash != v.prev.hash {
+				return fail(n, e.Seq, "previous hash does not match record %d", v.prev.seq)
+			}
+		} else {
+			if e.Kind != KindCheckpoint {
+				return fail(n, e.Seq, "chain does not start with a checkpoint; records before it were removed")
+			}
+			v.FirstSeq = e.Seq
+		}
+		v.prev = &link{seq: e.Seq, hash: hash}
+		if e.Kind != KindCheckpoint {
+			v.Unsigned++
+			return true
+		}
+		sig, err := base64.StdEncoding.DecodeString(e.Signature)
+		if err != nil || !ed25519.Verify(v.pub, []byte(e.PrevHash), sig) {
+			return fail(n, e.Seq, "checkpoint signature is invalid")
+		}
+		// The signature covers the records since the previous checkpoint.
+		v.Records += v.Unsigned + 1
+		v.Checkpoints++
+		v.Unsigned = 0
+		v.LastSeq = e.Seq
+		return true
+	})
+}
@@ -0,0 +1,205 @@
+package audit
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useTestLog points the audit log, its keys and its rotation settings at a
+// temporary directory for the rest of the test.
+func useTestLog(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	defer func(dir, key, pub string, interval int, size int64, age, retention time.Duration) {
+		t.Cleanup(func() {
+			Dir, KeyFile, PublicKeyFile = dir, key, pub
+			CheckpointInterval, MaxSegmentSize, MaxSegmentAge, Retention = interval, size, age, retention
+		})
+	}(Dir, KeyFile, PublicKeyFile, CheckpointInterval, MaxSegmentSize, MaxSegmentAge, Retention)
+	Dir = filepath.Join(dir, "log")
+	KeyFile = filepath.Join(dir, "keys", "audit.key")
+	PublicKeyFile = filepath.Join(dir, "audit.pub")
+	CheckpointInterval, MaxSegmentSize, MaxSegmentAge, Retention = 3, 0, 0, 0
+}
+
+// writeTestLog records five events with a checkpoint every three records
+// and returns the lines of the log, with checkpoints at seq 1, 5 and 8, and
+// the public key checking them.
+func writeTestLog(t *testing.T) ([]string, ed25519.PublicKey) {
+	t.Helper()
+	useTestLog(t)
+	InitAuditLogger()
+	for _, user := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		Record(Event{Principal: user, Action: "QueryData", Outcome: OutcomeSuccess})
+	}
+	if err := Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(Dir, currentSegment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := loadPublicKey(PublicKeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"), pub
+}
+
+// verifyLines verifies lines as a single segment against pub.
+func verifyLines(t *testing.T, lines []string, pub ed25519.PublicKey) *Verification {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, currentSegment), []byte(strings.Join(lines, "\n")+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	v, err := verifyLog(dir, pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+// rechain renumbers and re-hashes lines from index from on, as someone
+// rewriting the log would, signing checkpoints with key.
+func rechain(t *testing.T, lines []string, from int, key ed25519.PrivateKey) []string {
+	t.Helper()
+	parse := func(line string) Event {
+		body, _, ok := splitRecord(line)
+		var e Event
+		if !ok || json.Unmarshal([]byte(body), &e) != nil {
+			t.Fatalf("not a chained record: %s", line)
+		}
+		return e
+	}
+	_, prev, _ := splitRecord(lines[from-1])
+	seq := parse(lines[from-1]).Seq
+	out := append([]string(nil), lines[:from]...)
+	for _, line := range lines[from:] {
+		e := parse(line)
+		seq++
+		e.Seq, e.PrevHash = seq, prev
+		if e.Kind == KindCheckpoint {
+			e.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, []byte(prev)))
+		}
+		encoded, err := encodeEvent(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prev = hashRecord(encoded)
+		out = append(out, string(encoded[:len(encoded)-1])+hashField+prev+`"}`)
+	}
+	return out
+}
+
+func TestVerifyAuditLog(t *testing.T) {
+	lines, pub := writeTestLog(t)
+	_, forger, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 8 {
+		t.Fatalf("wrote %d records, want 8", len(lines))
+	}
+
+	tampered := append([]string(nil), lines...)
+	tampered[2] = strings.Replace(tampered[2], `"principal":"bob"`, `"principal":"mallory"`, 1)
+	reordered := append([]string(nil), lines...)
+	reordered[2], reordered[3] = reordered[3], reordered[2]
+	cut := append([]string(nil), lines...)
+	cut[7] = cut[7][:len(cut[7])-20]
+	// Signed with the forger's key, every hash links up again.
+	forged := rechain(t, tampered, 2, forger)
+	// Records appended after the last checkpoint cannot be signed without
+	// the key.
+	extended := rechain(t, append(append([]string(nil), lines...), lines[5], lines[6]), 8, forger)
+
+	tests := []struct {
+		name     string
+		lines    []string
+		pub      ed25519.PublicKey
+		valid    bool
+		reason   string
+		line     int64
+		records  int64
+		unsigned int64
+	}{
+		{name: "intact", lines: lines, pub: pub, valid: true, records: 8},
+		{name: "tampered body", lines: tampered, pub: pub, reason: "does not match its hash", line: 3},
+		{name: "reordered record", lines: reordered, pub: pub, reason: "expected sequence number 3, found 4", line: 3},
+		{name: "truncated line", lines: cut, pub: pub, reason: "record has no hash", line: 8},
+		{name: "truncated tail", lines: lines[:6], pub: pub, valid: true, records: 5, unsigned: 1},
+		{name: "unsigned tail", lines: extended, pub: pub, valid: true, records: 8, unsigned: 2},
+		{name: "forged checkpoint", lines: forged, pub: pub, reason: "checkpoint signature is invalid", line: 5},
+		{name: "wrong public key", lines: lines, pub: forger.Public().(ed25519.PublicKey), reason: "checkpoint signature is invalid", line: 1},
+		{name: "missing head", lines: lines[1:], pub: pub, reason: "does not start with a checkpoint", line: 1},
+	}
+	for _, tt := range tests {
+		v := verifyLines(t, tt.lines, tt.pub)
+		if v.Valid != tt.valid || !strings.Contains(v.Reason, tt.reason) || v.BrokenLine != tt.line {
+			t.Errorf("%s: valid %v, line %d, reason %q; want %v, %d, %q", tt.name, v.Valid, v.BrokenLine, v.Reason, tt.valid, tt.line, tt.reason)
+		}
+		if tt.valid && (v.Records != tt.records || v.Unsigned != tt.unsigned) {
+			t.Errorf("%s: %d records, %d unsigned; want %d, %d", tt.name, v.Records, v.Unsigned, tt.records, tt.unsigned)
+		}
+	}
+}
+
+func TestVerifyAuditLogWithPublicKeyOnly(t *testing.T) {
+	writeTestLog(t)
+	if err := os.Remove(KeyFile); err != nil {
+		t.Fatal(err)
+	}
+	v, err := VerifyAuditLog()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.Valid || v.Records != 8 {
+		t.Errorf("valid %v with %d records, want a valid log of 8: %s", v.Valid, v.Records, v.Reason)
+	}
+}
+
+func TestSigningKey(t *testing.T) {
+	useTestLog(t)
+	if err := os.MkdirAll(Dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	// Earlier versions kept the key in Dir.
+	seed := make([]byte, ed25519.SeedSize)
+	rand.Read(seed)
+	legacy := filepath.Join(Dir, legacyKeyFile)
+	if err := os.WriteFile(legacy, []byte(hex.EncodeToString(seed)+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	key, err := signingKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !key.Equal(ed25519.NewKeyFromSeed(seed)) {
+		t.Error("the key in the log directory was not kept")
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("the key was left in the log directory: %v", err)
+	}
+	pub, err := loadPublicKey(PublicKeyFile)
+	if err != nil || !pub.Equal(key.Public()) {
+		t.Errorf("public key file holds %x, %v; want %x", pub, err, key.Public())
+	}
+
+	// A public key that does not match is not overwritten.
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	if err := os.WriteFile(PublicKeyFile, []byte(hex.EncodeToString(other)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signingKey(); err == nil {
+		t.Error("signingKey accepted a public key file for another key")
+	}
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Query selects audit events. Empty fields match everything; Since is
+// inclusive and Until exclusive.
+type Query struct {
+	Principal string
+	Database  string
+	Action    string
+	Since     time.Time
+	Until     time.Time
+}
+
+func (q Query) matches(e *Event) bool {
+	return (q.Principal == "" || e.Principal == q.Principal) &&
+		(q.Database == "" || e.Database == q.Database) &&
+		(q.Action == "" || strings.EqualFold(e.Action, q.Action)) &&
+		(q.Since.IsZero() || !e.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || e.Time.Before(q.Until))
+}
+
+// Search returns up to limit events matching q, oldest first, across the
+// rotated and current segments; more reports whether further events match.
+// A limit of zero returns every match. Segments closed before q.Since are
+// not read.
+func Search(q Query, limit int64) (events []Event, more bool, err error) {
+	list, err := segments(Dir)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, seg := range list {
+		if !seg.closed.IsZero() && !q.Since.IsZero() && seg.closed.Before(q.Since) {
+			continue
+		}
+		r, err := seg.open()
+		if err != nil {
+			return nil, false, err
+		}
+		err = readLines(r, func(_ int64, line string) bool {
+			body, _, ok := splitRecord(line)
+			if !ok {
+				body = line
+			}
+			var e Event
+			if json.Unmarshal([]byte(body), &e) != nil || !q.matches(&e) {
+				return true
+			}
+			if limit > 0 && int64(len(events)) == limit {
+				more = true
+				return false
+			}
+			events = append(events, e)
+			return true
+		})
+		r.Close()
+		if err != nil || more {
+			return events, more, err
+		}
+	}
+	return events, false, nil
+}
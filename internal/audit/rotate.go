@@ -0,0 +1,259 @@
+package audit
+
+import (
+	"compress/gzip"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
+)
+
+// Audit log location and rotation settings. They are read when the logger
+// starts.
+var (
+	// Dir holds the current segment, the rotated ones and the signing key.
+	// Usernames cannot start with "_", so it never clashes with a user's
+	// database directory.
+	Dir = filepath.Join(db.DBDir, "_audit")
+	// MaxSegmentSize rotates the current segment once it reaches this many
+	// bytes. Zero disables size-based rotation.
+	MaxSegmentSize int64 = 64 << 20
+	// MaxSegmentAge rotates the current segment once its first record is
+	// this old. Zero disables time-based rotation.
+	MaxSegmentAge = 24 * time.Hour
+	// Retention removes rotated segments older than this. Zero keeps them
+	// forever.
+	Retention = 90 * 24 * time.Hour
+	// Compress gzips rotated segments.
+	Compress = true
+)
+
+const (
+	currentSegment = "audit.log"
+	segmentPrefix  = "audit-"
+	segmentSuffix  = ".log"
+	gzipSuffix     = ".gz"
+	// segmentTimeFormat stamps rotated segments with the time they were
+	// closed; it sorts lexically.
+	segmentTimeFormat = "20060102T150405.000Z"
+)
+
+// segment is one file of the audit log.
+type segment struct {
+	path string
+	// closed is when a rotated segment stopped receiving records; zero for
+	// the current segment.
+	closed     time.Time
+	compressed bool
+}
+
+// segments lists the audit log's segments from oldest to newest, ending
+// with the current one if it exists.
+func segments(dir string) ([]segment, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	byTime := map[time.Time]segment{}
+	var current []segment
+	for _, entry := range entries {
+		name := entry.Name()
+		if name == currentSegment {
+			current = append(current, segment{path: filepath.Join(dir, name)})
+			continue
+		}
+		stamp, compressed := strings.CutSuffix(name, gzipSuffix)
+		stamp, ok := strings.CutPrefix(stamp, segmentPrefix)
+		if !ok {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(stamp, segmentSuffix)
+		if !ok {
+			continue
+		}
+		closed, err := time.Parse(segmentTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		// A segment being compressed exists in both forms; the .gz file
+		// only appears once it is complete.
+		if prev, seen := byTime[closed]; seen && prev.compressed {
+			continue
+		}
+		byTime[closed] = segment{path: filepath.Join(dir, name), closed: closed, compressed: compressed}
+	}
+
+	list := make([]segment, 0, len(byTime)+len(current))
+	for _, seg := range byTime {
+		list = append(list, seg)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].closed.Before(list[j].closed) })
+	return append(list, current...), nil
+}
+
+// open returns a reader over the segment's records.
+func (s segment) open() (io.ReadCloser, error) {
+	file, err := os.Open(s.path)
+	if err != nil || !s.compressed {
+		return file, err
+	}
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{zr, file}, nil
+}
+
+// segmentWriter appends to the current segment and rotates it.
+type segmentWriter struct {
+	dir     string
+	file    *os.File
+	size    int64
+	started time.Time
+	// compressing tracks background compression of rotated segments.
+	compressing sync.WaitGroup
+}
+
+// openSegmentWriter opens (or creates) the current segment in dir. started
+// is the time of its first record, or zero if it has none.
+func openSegmentWriter(dir string, started time.Time) (*segmentWriter, error) {
+	w := &segmentWriter{dir: dir}
+	if err := w.open(started); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *segmentWriter) open(started time.Time) error {
+	file, err := os.OpenFile(filepath.Join(w.dir, currentSegment), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if started.IsZero() {
+		started = time.Now()
+	}
+	w.file, w.size, w.started = file, info.Size(), started
+	return nil
+}
+
+func (w *segmentWriter) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// due reports whether the current segment should be rotated before the next
+// record is written.
+func (w *segmentWriter) due(now time.Time) bool {
+	if w.size == 0 {
+		return false
+	}
+	return (MaxSegmentSize > 0 && w.size >= MaxSegmentSize) ||
+		(MaxSegmentAge > 0 && now.Sub(w.started) >= MaxSegmentAge)
+}
+
+// rotate closes the current segment under a timestamped name, starts a new
+// one and applies retention. Compression runs in the background.
+func (w *segmentWriter) rotate(now time.Time) error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	rotated := filepath.Join(w.dir, segmentPrefix+now.UTC().Format(segmentTimeFormat)+segmentSuffix)
+	if err := os.Rename(filepath.Join(w.dir, currentSegment), rotated); err != nil {
+		return err
+	}
+	if err := w.open(now); err != nil {
+		return err
+	}
+	if Compress {
+		w.compressing.Add(1)
+		go func() {
+			defer w.compressing.Done()
+			if err := compressSegment(rotated); err != nil {
+				log.Printf("AUDIT: failed to compress %s: %v", rotated, err)
+			}
+		}()
+	}
+	return prune(w.dir, now)
+}
+
+// Close waits for pending compression, then syncs and closes the current
+// segment.
+func (w *segmentWriter) Close() error {
+	w.compressing.Wait()
+	if err := w.file.Sync(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+// compressSegment gzips a rotated segment and removes the original.
+func compressSegment(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + gzipSuffix + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path+gzipSuffix)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(path)
+}
+
+// prune removes rotated segments that closed longer than Retention ago.
+func prune(dir string, now time.Time) error {
+	if Retention <= 0 {
+		return nil
+	}
+	list, err := segments(dir)
+	if err != nil {
+		return err
+	}
+	for _, seg := range list {
+		if seg.closed.IsZero() || now.Sub(seg.closed) < Retention {
+			continue
+		}
+		if err := os.Remove(seg.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		log.Printf("AUDIT: removed expired segment %s", filepath.Base(seg.path))
+	}
+	return nil
+}
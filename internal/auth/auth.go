@@ -1,24 +1,47 @@
 package auth
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"regexp"
 
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/prakhar-5447/GoDB/internal/metrics"
 )
 
-const authDBPath = "data/auth.db"
+// DBPath is the auth database holding users, roles and server settings. It
+// is set from the configuration before the server starts.
+var DBPath = filepath.Join("data", "auth.db")
 
-// InitAuthDatabase ensures that the auth database and users table exist,
-// and inserts a default user if the table is empty.
+// ErrInvalidUsername is returned when a username fails validation.
+var ErrInvalidUsername = errors.New("invalid username")
+
+// usernamePattern allows letters, digits, underscores and hyphens only,
+// starting with a letter or digit, up to 64 characters. Usernames double as
+// directory names under the data directory, so nothing path-like is allowed.
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// ValidateUsername checks that a username is safe to use as a directory name.
+func ValidateUsername(username string) error {
+	if !usernamePattern.MatchString(username) {
+		return fmt.Errorf("%w: %q", ErrInvalidUsername, username)
+	}
+	return nil
+}
+
+// InitAuthDatabase ensures that the auth database, users and settings tables
+// exist, and creates the admin user named in the environment if configured.
 func InitAuthDatabase() error {
 	// Ensure the data directory exists.
-	if err := os.MkdirAll("data", os.ModePerm); err != nil {
+	if err := os.MkdirAll(filepath.Dir(DBPath), os.ModePerm); err != nil {
 		return fmt.Errorf("failed to create data directory: %w", err)
 	}
 
-	db, err := sql.Open("sqlite3", authDBPath)
+	db, err := sql.Open("sqlite3", DBPath)
 	if err != nil {
 		return fmt.Errorf("failed to open auth database: %w", err)
 	}
@@ -35,6 +58,24 @@ func InitAuthDatabase() error {
 		return fmt.Errorf("failed to create users table: %w", err)
 	}
 
+	// Databases created before roles existed lack the role column.
+	if err := ensureRoleColumn(db); err != nil {
+		return fmt.Errorf("failed to add role column: %w", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS settings (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);`)
+	if err != nil {
+		return fmt.Errorf("failed to create settings table: %w", err)
+	}
+
+	if err := bootstrapAdmin(db); err != nil {
+		return fmt.Errorf("failed to create admin user: %w", err)
+	}
+
 	// Check if there are any users
 	var count int
 	err = db.QueryRow("SELECT COUNT(*) FROM users").Scan(&count)
@@ -45,10 +86,31 @@ func InitAuthDatabase() error {
 	return nil
 }
 
+// CheckAuthDatabase reports whether the auth database can be read. It opens
+// the file read-only, so a missing database is an error rather than being
+// created.
+func CheckAuthDatabase(ctx context.Context) error {
+	db, err := sql.Open("sqlite3", "file:"+DBPath+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open auth database: %w", err)
+	}
+	defer db.Close()
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&count); err != nil {
+		return fmt.Errorf("failed to query auth database: %w", err)
+	}
+	return nil
+}
+
 // CreateUser inserts a new user with the provided username and password into the auth database.
 func CreateUser(username, password string) error {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+
 	// Open auth database.
-	db, err := sql.Open("sqlite3", authDBPath)
+	db, err := sql.Open("sqlite3", DBPath)
 	if err != nil {
 		return fmt.Errorf("failed to open auth database: %w", err)
 	}
@@ -65,7 +127,7 @@ func CreateUser(username, password string) error {
 // ValidateUserCredentials checks the users table for the given username and password.
 // Returns true if credentials match, false otherwise.
 func ValidateUserCredentials(username, password string) (bool, error) {
-	db, err := sql.Open("sqlite3", authDBPath)
+	db, err := sql.Open("sqlite3", DBPath)
 	if err != nil {
 		return false, fmt.Errorf("failed to open auth database: %w", err)
 	}
@@ -76,5 +138,8 @@ func ValidateUserCredentials(username, password string) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to query auth database: %w", err)
 	}
+	if count == 0 {
+		metrics.AuthFailures.Inc()
+	}
 	return count > 0, nil
 }
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"alice", true},
+		{"Bob_2", true},
+		{"9lives", true},
+		{"a-b", true},
+		{strings.Repeat("a", 64), true},
+		{strings.Repeat("a", 65), false},
+		{"", false},
+		{"..", false},
+		{".", false},
+		{"../bob", false},
+		{"alice/../bob", false},
+		{"/etc", false},
+		{`a\b`, false},
+		{"_hidden", false},
+		{"-flag", false},
+		{"a b", false},
+		{"a\x00b", false},
+		{"ålice", false},
+	}
+	for _, tt := range tests {
+		err := ValidateUsername(tt.name)
+		if tt.ok && err != nil {
+			t.Errorf("ValidateUsername(%q) = %v, want nil", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidUsername) {
+			t.Errorf("ValidateUsername(%q) = %v, want ErrInvalidUsername", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// User roles. Admins may call server-wide management RPCs.
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// ErrPermissionDenied is returned when a user lacks the role an operation requires.
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Environment variables used to bootstrap an admin account at startup.
+const (
+	adminUsernameEnv = "GODB_ADMIN_USERNAME"
+	adminPasswordEnv = "GODB_ADMIN_PASSWORD"
+)
+
+// ensureRoleColumn adds the role column to users tables created before roles existed.
+func ensureRoleColumn(db *sql.DB) error {
+	rows, err := db.Query("PRAGMA table_info(users)")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == "role" {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT '%s'", RoleUser))
+	return err
+}
+
+// bootstrapAdmin creates or promotes the admin account named by the
+// GODB_ADMIN_USERNAME and GODB_ADMIN_PASSWORD environment variables.
+func bootstrapAdmin(db *sql.DB) error {
+	username, password := os.Getenv(adminUsernameEnv), os.Getenv(adminPasswordEnv)
+	if username == "" || password == "" {
+		return nil
+	}
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+	_, err := db.Exec(`INSERT INTO users (username, password, role) VALUES (?, ?, ?)
+		ON CONFLICT(username) DO UPDATE SET password = excluded.password, role = excluded.role`,
+		username, password, RoleAdmin)
+	return err
+}
+
+// UserRole returns the role of a user.
+func UserRole(username string) (string, error) {
+	db, err := sql.Open("sqlite3", DBPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open auth database: %w", err)
+	}
+	defer db.Close()
+
+	var role string
+	err = db.QueryRow("SELECT role FROM users WHERE username = ?", username).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("user '%s' not found", username)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to query auth database: %w", err)
+	}
+	return role, nil
+}
+
+// SetUserRole changes the role of an existing user.
+func SetUserRole(username, role string) error {
+	if role != RoleUser && role != RoleAdmin {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	db, err := sql.Open("sqlite3", DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open auth database: %w", err)
+	}
+	defer db.Close()
+
+	res, err := db.Exec("UPDATE users SET role = ? WHERE username = ?", role, username)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("user '%s' not found", username)
+	}
+	return nil
+}
+
+// RequireAdmin validates credentials and checks that the user is an admin.
+func RequireAdmin(username, password string) error {
+	ok, err := ValidateUserCredentials(username, password)
+	if err != nil || !ok {
+		return fmt.Errorf("authentication failed")
+	}
+	role, err := UserRole(username)
+	if err != nil {
+		return err
+	}
+	if role != RoleAdmin {
+		return fmt.Errorf("%w: user '%s' is not an admin", ErrPermissionDenied, username)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetSetting reads a server-wide setting stored in the auth database.
+// The boolean result is false when the setting has never been written.
+func GetSetting(key string) (string, bool, error) {
+	db, err := sql.Open("sqlite3", DBPath)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open auth database: %w", err)
+	}
+	defer db.Close()
+
+	var value string
+	err = db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read setting %s: %w", key, err)
+	}
+	return value, true, nil
+}
+
+// SetSetting stores a server-wide setting in the auth database.
+func SetSetting(key, value string) error {
+	db, err := sql.Open("sqlite3", DBPath)
+	if err != nil {
+		return fmt.Errorf("failed to open auth database: %w", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value", key, value)
+	if err != nil {
+		return fmt.Errorf("failed to write setting %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"sync"
+)
+
+// verification records the user whose credentials were verified while
+// handling a request.
+type verification struct {
+	mu       sync.Mutex
+	username string
+}
+
+type verificationKey struct{}
+
+// WithVerification returns a context in which MarkVerified records the
+// verified user, for VerifiedUser to report once the request is handled.
+func WithVerification(ctx context.Context) context.Context {
+	return context.WithValue(ctx, verificationKey{}, &verification{})
+}
+
+// MarkVerified records that username's credentials were verified. It does
+// nothing unless ctx comes from WithVerification.
+func MarkVerified(ctx context.Context, username string) {
+	if v, ok := ctx.Value(verificationKey{}).(*verification); ok {
+		v.mu.Lock()
+		v.username = username
+		v.mu.Unlock()
+	}
+}
+
+// VerifiedUser returns the user MarkVerified recorded in ctx, if any.
+func VerifiedUser(ctx context.Context) (string, bool) {
+	v, ok := ctx.Value(verificationKey{}).(*verification)
+	if !ok {
+		return "", false
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.username, v.username != ""
+}
@@ -0,0 +1,369 @@
+// Package config gathers the server's settings from built-in defaults, an
+// optional YAML, TOML or JSON file, GODB_* environment variables and
+// command-line flags, each overriding the one before, and validates them
+// before the server starts.
+package config
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/prakhar-5447/GoDB/internal/slowlog"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the server configuration. Empty paths are filled in relative
+// to DataDir when the configuration is loaded.
+type Config struct {
+	ListenAddr string `json:"listen_addr"`
+	// AdvertiseAddr is the host:port put in the connection strings
+	// CreateUser returns, for servers behind a proxy or a published port.
+	// It defaults to ListenAddr, with localhost for a wildcard host.
+	AdvertiseAddr string `json:"advertise_addr"`
+	// DataDir holds the user databases and, by default, everything else
+	// the server writes.
+	DataDir  string   `json:"data_dir"`
+	AuthDB   string   `json:"auth_db"`
+	Limits   Limits   `json:"limits"`
+	TLS      TLS      `json:"tls"`
+	Logging  Logging  `json:"logging"`
+	Features Features `json:"features"`
+}
+
+type Limits struct {
+	// MaxRows caps the rows a single query may return.
+	MaxRows int64 `json:"max_rows"`
+	// ShutdownTimeout is how long in-flight RPCs may run after a stop
+	// signal before they are cancelled.
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+}
+
+// TLS serves gRPC over TLS when CertFile and KeyFile are set. With
+// ClientCAFile, clients must present a certificate signed by that CA.
+type TLS struct {
+	CertFile     string `json:"cert_file"`
+	KeyFile      string `json:"key_file"`
+	ClientCAFile string `json:"client_ca_file"`
+}
+
+// Enabled reports whether a certificate is configured.
+func (t TLS) Enabled() bool { return t.CertFile != "" }
+
+// ServerConfig loads the certificate, key and client CA.
+func (t TLS) ServerConfig() (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if t.ClientCAFile != "" {
+		pem, err := os.ReadFile(t.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s contains no PEM certificates", t.ClientCAFile)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+type Logging struct {
+	Audit     Audit     `json:"audit"`
+	SlowQuery SlowQuery `json:"slow_query"`
+}
+
+type Audit struct {
+	Dir string `json:"dir"`
+	// KeyFile is the signing key, which must not be inside Dir.
+	// PublicKeyFile is all -verify-audit-log and VerifyAuditLog need.
+	KeyFile          string   `json:"key_file"`
+	PublicKeyFile    string   `json:"public_key_file"`
+	MaxSize          int64    `json:"max_size"`
+	MaxAge           Duration `json:"max_age"`
+	Retention        Duration `json:"retention"`
+	Compress         bool     `json:"compress"`
+	SensitiveColumns []string `json:"sensitive_columns"`
+}
+
+type SlowQuery struct {
+	Log string `json:"log"`
+	// Threshold is a duration, optionally followed by per-operation
+	// thresholds, e.g. "500ms,select=1s".
+	Threshold string `json:"threshold"`
+}
+
+type Features struct {
+	Metrics    Metrics `json:"metrics"`
+	Tracing    Tracing `json:"tracing"`
+	Reflection bool    `json:"reflection"`
+}
+
+type Metrics struct {
+	Enabled bool `json:"enabled"`
+	// Addr defaults to the loopback interface: the metrics are served
+	// without authentication and name every user and database.
+	Addr string `json:"addr"`
+}
+
+type Tracing struct {
+	// Exporter is "off", "file" or "otlp".
+	Exporter     string `json:"exporter"`
+	File         string `json:"file"`
+	OTLPEndpoint string `json:"otlp_endpoint"`
+}
+
+// Duration is a time.Duration written as a string such as "30s".
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Default returns the configuration the server runs with when nothing is
+// configured.
+func Default() *Config {
+	return &Config{
+		ListenAddr: ":50051",
+		DataDir:    "data",
+		Limits: Limits{
+			MaxRows:         10000,
+			ShutdownTimeout: Duration(8 * time.Second),
+		},
+		Logging: Logging{
+			Audit: Audit{
+				MaxSize:          64 << 20,
+				MaxAge:           Duration(24 * time.Hour),
+				Retention:        Duration(90 * 24 * time.Hour),
+				Compress:         true,
+				SensitiveColumns: []string{},
+			},
+			SlowQuery: SlowQuery{Threshold: "500ms"},
+		},
+		Features: Features{
+			Metrics: Metrics{Enabled: true, Addr: "127.0.0.1:9090"},
+			Tracing: Tracing{Exporter: "off", OTLPEndpoint: "http://localhost:4318/v1/traces"},
+		},
+	}
+}
+
+// Load reads the configuration file named by the -config flag or
+// GODB_CONFIG, if any, applies the environment and the flags that were set,
+// fills in derived paths and validates the result.
+func Load(flags *Flags) (*Config, error) {
+	c := Default()
+	path := os.Getenv("GODB_CONFIG")
+	if flags != nil && flags.path != "" {
+		path = flags.path
+	}
+	if path != "" {
+		if err := c.loadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	errs := errors.Join(c.applyEnv(), flags.apply(c))
+	c.resolve()
+	if err := errors.Join(errs, c.Validate()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// loadFile decodes the file at path over c. The format follows the
+// extension; unknown keys are errors so that typos do not go unnoticed.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	// YAML and TOML are decoded generically and re-encoded as JSON, so that
+	// every format shares the JSON field names and strictness.
+	var generic interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, &generic); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("config file %s: unsupported format %q; use .yaml, .toml or .json", path, ext)
+	}
+	if generic != nil {
+		if data, err = json.Marshal(generic); err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(c); err != nil && err != io.EOF {
+		// The JSON step is an implementation detail for YAML and TOML files.
+		return fmt.Errorf("config file %s: %s", path, strings.TrimPrefix(err.Error(), "json: "))
+	}
+	return nil
+}
+
+// resolve places unset paths under the data directory.
+func (c *Config) resolve() {
+	defaults := []struct {
+		path *string
+		name string
+	}{
+		{&c.AuthDB, "auth.db"},
+		{&c.Logging.Audit.Dir, "_audit"},
+		{&c.Logging.Audit.KeyFile, "audit.key"},
+		{&c.Logging.SlowQuery.Log, "slow_queries.log"},
+		{&c.Features.Tracing.File, "traces.jsonl"},
+	}
+	for _, d := range defaults {
+		if *d.path == "" {
+			*d.path = filepath.Join(c.DataDir, d.name)
+		}
+	}
+	if c.Logging.Audit.PublicKeyFile == "" {
+		c.Logging.Audit.PublicKeyFile = c.Logging.Audit.KeyFile + ".pub"
+	}
+	if c.AdvertiseAddr == "" {
+		if host, port, err := net.SplitHostPort(c.ListenAddr); err == nil {
+			if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+				host = "localhost"
+			}
+			c.AdvertiseAddr = net.JoinHostPort(host, port)
+		}
+	}
+	if c.Features.Tracing.Exporter == "" {
+		c.Features.Tracing.Exporter = "off"
+	}
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(c.ListenAddr); err != nil {
+		fail("listen_addr %q: %v", c.ListenAddr, err)
+	}
+	if host, _, err := net.SplitHostPort(c.AdvertiseAddr); err != nil || host == "" {
+		fail("advertise_addr %q must be a host and port", c.AdvertiseAddr)
+	}
+	if c.DataDir == "" {
+		fail("data_dir must not be empty")
+	}
+	if c.Limits.MaxRows < 0 {
+		fail("limits.max_rows must not be negative")
+	}
+	if c.Limits.ShutdownTimeout < 0 {
+		fail("limits.shutdown_timeout must not be negative")
+	}
+
+	switch {
+	case c.TLS.CertFile != "" && c.TLS.KeyFile == "":
+		fail("tls.key_file is required when tls.cert_file is set")
+	case c.TLS.CertFile == "" && c.TLS.KeyFile != "":
+		fail("tls.cert_file is required when tls.key_file is set")
+	case c.TLS.CertFile == "" && c.TLS.ClientCAFile != "":
+		fail("tls.client_ca_file requires tls.cert_file and tls.key_file")
+	}
+	for name, path := range map[string]string{
+		"tls.cert_file":      c.TLS.CertFile,
+		"tls.key_file":       c.TLS.KeyFile,
+		"tls.client_ca_file": c.TLS.ClientCAFile,
+	} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			fail("%s: %v", name, err)
+		}
+	}
+
+	audit := c.Logging.Audit
+	if audit.MaxSize < 0 {
+		fail("logging.audit.max_size must not be negative")
+	}
+	if audit.MaxAge < 0 || audit.Retention < 0 {
+		fail("logging.audit.max_age and logging.audit.retention must not be negative")
+	}
+	// Whoever can rewrite the log must not be able to re-sign it.
+	for name, path := range map[string]string{
+		"logging.audit.key_file":        audit.KeyFile,
+		"logging.audit.public_key_file": audit.PublicKeyFile,
+	} {
+		if within(audit.Dir, path) {
+			fail("%s must not be inside logging.audit.dir", name)
+		}
+	}
+	if _, err := slowlog.ParseThresholds(c.Logging.SlowQuery.Threshold); err != nil {
+		fail("logging.slow_query.threshold: %v", err)
+	}
+
+	if c.Features.Metrics.Enabled {
+		if _, _, err := net.SplitHostPort(c.Features.Metrics.Addr); err != nil {
+			fail("features.metrics.addr %q: %v", c.Features.Metrics.Addr, err)
+		} else if c.Features.Metrics.Addr == c.ListenAddr {
+			fail("features.metrics.addr must differ from listen_addr")
+		}
+	}
+	switch c.Features.Tracing.Exporter {
+	case "off", "file":
+	case "otlp":
+		if u, err := url.Parse(c.Features.Tracing.OTLPEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("features.tracing.otlp_endpoint %q must be an http or https URL", c.Features.Tracing.OTLPEndpoint)
+		}
+	default:
+		fail("features.tracing.exporter %q must be off, file or otlp", c.Features.Tracing.Exporter)
+	}
+	return errors.Join(errs...)
+}
+
+// Print writes c as indented JSON, which Load accepts as a config file.
+func (c *Config) Print(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(c)
+}
+
+// within reports whether path lies inside dir.
+func within(dir, path string) bool {
+	dirAbs, err := filepath.Abs(dir)
+	if err != nil {
+		return false
+	}
+	pathAbs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dirAbs, pathAbs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
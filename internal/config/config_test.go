@@ -0,0 +1,250 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every variable Load reads for the duration of the test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("GODB_CONFIG", "")
+	for _, s := range settings {
+		t.Setenv(s.env, "")
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// parseFlags registers the flags on a fresh set and parses args.
+func parseFlags(t *testing.T, args ...string) *Flags {
+	t.Helper()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags := RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return flags
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "godb.yaml", `
+listen_addr: ":1001"
+data_dir: /file
+limits:
+  max_rows: 5
+  shutdown_timeout: 3s
+features:
+  reflection: true
+`)
+	t.Setenv("GODB_LISTEN_ADDR", ":1002")
+	t.Setenv("GODB_MAX_ROWS", "6")
+	flags := parseFlags(t, "-config", path, "-listen-addr", ":1003")
+
+	c, err := Load(flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ListenAddr != ":1003" {
+		t.Errorf("listen_addr = %q, want the flag's :1003", c.ListenAddr)
+	}
+	if c.Limits.MaxRows != 6 {
+		t.Errorf("max_rows = %d, want the environment's 6", c.Limits.MaxRows)
+	}
+	if c.DataDir != "/file" || time.Duration(c.Limits.ShutdownTimeout) != 3*time.Second || !c.Features.Reflection {
+		t.Errorf("file settings were not kept: %+v", c)
+	}
+	if c.Logging.Audit.MaxSize != 64<<20 {
+		t.Errorf("audit max_size = %d, want the default", c.Logging.Audit.MaxSize)
+	}
+	if c.AuthDB != filepath.Join("/file", "auth.db") || c.Logging.Audit.Dir != filepath.Join("/file", "_audit") {
+		t.Errorf("derived paths not under data_dir: auth_db %q, audit dir %q", c.AuthDB, c.Logging.Audit.Dir)
+	}
+	if c.AdvertiseAddr != "localhost:1003" {
+		t.Errorf("advertise_addr = %q, want localhost:1003", c.AdvertiseAddr)
+	}
+}
+
+func TestLoadConfigFromEnvironment(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "godb.toml", "listen_addr = \"127.0.0.1:2001\"\n")
+	t.Setenv("GODB_CONFIG", path)
+	t.Setenv("GODB_METRICS_ADDR", "off")
+
+	c, err := Load(parseFlags(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ListenAddr != "127.0.0.1:2001" || c.AdvertiseAddr != "127.0.0.1:2001" {
+		t.Errorf("listen_addr %q, advertise_addr %q; want 127.0.0.1:2001 from the file", c.ListenAddr, c.AdvertiseAddr)
+	}
+	if c.Features.Metrics.Enabled {
+		t.Error("GODB_METRICS_ADDR=off left metrics enabled")
+	}
+}
+
+func TestLoadDataDirFallback(t *testing.T) {
+	tests := []struct {
+		name  string
+		file  string
+		env   map[string]string
+		flags []string
+		want  string
+	}{
+		{"default", "", nil, nil, "data"},
+		{"legacy variable", "", map[string]string{"DB_DATA_DIR": "/legacy"}, nil, "/legacy"},
+		{"legacy variable over file", `{"data_dir": "/file"}`, map[string]string{"DB_DATA_DIR": "/legacy"}, nil, "/legacy"},
+		{"GODB_DATA_DIR over legacy", "", map[string]string{"DB_DATA_DIR": "/legacy", "GODB_DATA_DIR": "/godb"}, nil, "/godb"},
+		{"flag over both", "", map[string]string{"DB_DATA_DIR": "/legacy", "GODB_DATA_DIR": "/godb"}, []string{"-data-dir", "/flag"}, "/flag"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			args := tt.flags
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, "godb.json", tt.file)}, args...)
+			}
+			c, err := Load(parseFlags(t, args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.DataDir != tt.want {
+				t.Errorf("data_dir = %q, want %q", c.DataDir, tt.want)
+			}
+			if c.AuthDB != filepath.Join(tt.want, "auth.db") {
+				t.Errorf("auth_db = %q, want it under %q", c.AuthDB, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadReportsEveryError(t *testing.T) {
+	clearEnv(t)
+	path := writeFile(t, "godb.yaml", `
+listen_addr: "no-port"
+data_dir: ""
+limits:
+  max_rows: -1
+tls:
+  key_file: /missing/key.pem
+logging:
+  slow_query:
+    threshold: soon
+features:
+  tracing:
+    exporter: jaeger
+`)
+	t.Setenv("GODB_AUDIT_MAX_SIZE", "big")
+	flags := parseFlags(t, "-config", path, "-shutdown-timeout", "later")
+
+	_, err := Load(flags)
+	if err == nil {
+		t.Fatal("Load accepted an invalid configuration")
+	}
+	for _, want := range []string{
+		`invalid GODB_AUDIT_MAX_SIZE "big"`,
+		`invalid -shutdown-timeout "later"`,
+		`listen_addr "no-port"`,
+		"data_dir must not be empty",
+		"limits.max_rows must not be negative",
+		"tls.cert_file is required",
+		"tls.key_file: stat /missing/key.pem",
+		"logging.slow_query.threshold",
+		`features.tracing.exporter "jaeger"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+		want   string
+	}{
+		{"defaults", func(*Config) {}, ""},
+		{"metrics on the gRPC address", func(c *Config) { c.Features.Metrics.Addr = c.ListenAddr }, "must differ from listen_addr"},
+		{"metrics address without port", func(c *Config) { c.Features.Metrics.Addr = "localhost" }, "features.metrics.addr"},
+		{"disabled metrics are not checked", func(c *Config) { c.Features.Metrics = Metrics{Addr: "bad"} }, ""},
+		{"advertise without host", func(c *Config) { c.AdvertiseAddr = ":50051" }, "advertise_addr"},
+		{"negative shutdown timeout", func(c *Config) { c.Limits.ShutdownTimeout = -1 }, "limits.shutdown_timeout"},
+		{"client CA without certificate", func(c *Config) { c.TLS.ClientCAFile = "ca.pem" }, "tls.client_ca_file requires"},
+		{"negative audit retention", func(c *Config) { c.Logging.Audit.Retention = -1 }, "logging.audit.max_age and logging.audit.retention"},
+		{"audit key in the log directory", func(c *Config) { c.Logging.Audit.KeyFile = filepath.Join(c.Logging.Audit.Dir, "audit.key") }, "logging.audit.key_file must not be inside"},
+		{"audit public key in the log directory", func(c *Config) {
+			c.Logging.Audit.PublicKeyFile = filepath.Join(c.Logging.Audit.Dir, "keys", "audit.pub")
+		}, "logging.audit.public_key_file must not be inside"},
+		{"audit key beside the log directory", func(c *Config) { c.Logging.Audit.KeyFile = c.Logging.Audit.Dir + ".key" }, ""},
+		{"otlp without URL", func(c *Config) { c.Features.Tracing = Tracing{Exporter: "otlp", OTLPEndpoint: "localhost:4318"} }, "otlp_endpoint"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			c.resolve()
+			tt.modify(c)
+			err := c.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Errorf("Validate: %v", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Errorf("Validate = %v, want an error mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileRejectsUnknownKeys(t *testing.T) {
+	for name, content := range map[string]string{
+		"godb.yaml": "listen_adr: \":1\"\n",
+		"godb.toml": "[limits]\nmax_row = 1\n",
+		"godb.json": `{"features": {"metric": {}}}`,
+	} {
+		if err := Default().loadFile(writeFile(t, name, content)); err == nil || !strings.Contains(err.Error(), "unknown field") {
+			t.Errorf("%s: loadFile = %v, want an unknown field error", name, err)
+		}
+	}
+	if err := Default().loadFile(writeFile(t, "godb.ini", "")); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+		t.Errorf("loadFile of an .ini file = %v, want an unsupported format error", err)
+	}
+}
+
+func TestPrintRoundTrips(t *testing.T) {
+	clearEnv(t)
+	c := Default()
+	c.ListenAddr = "127.0.0.1:3001"
+	c.Logging.Audit.SensitiveColumns = []string{"ssn"}
+	c.resolve()
+
+	var out strings.Builder
+	if err := c.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(parseFlags(t, "-config", writeFile(t, "printed.json", out.String())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var again strings.Builder
+	if err := loaded.Print(&again); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != out.String() {
+		t.Errorf("printed configuration does not load back unchanged:\n%s\nvs\n%s", out.String(), again.String())
+	}
+}
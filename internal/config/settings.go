@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting is one value that can be overridden from the environment and,
+// when flag is set, from the command line.
+type setting struct {
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+// settings lists every override. Later entries win when several of them are
+// present, which is how GODB_DATA_DIR takes precedence over DB_DATA_DIR.
+var settings = []setting{
+	{"GODB_LISTEN_ADDR", "listen-addr", "gRPC listen address", field(func(c *Config) *string { return &c.ListenAddr }, parseString)},
+	{"GODB_ADVERTISE_ADDR", "advertise-addr", "host:port put in the connection strings CreateUser returns", field(func(c *Config) *string { return &c.AdvertiseAddr }, parseString)},
+	{"DB_DATA_DIR", "", "", field(func(c *Config) *string { return &c.DataDir }, parseString)},
+	{"GODB_DATA_DIR", "data-dir", "directory holding the databases and logs", field(func(c *Config) *string { return &c.DataDir }, parseString)},
+	{"GODB_AUTH_DB", "", "", field(func(c *Config) *string { return &c.AuthDB }, parseString)},
+	{"GODB_MAX_ROWS", "max-rows", "maximum rows a query may return", field(func(c *Config) *int64 { return &c.Limits.MaxRows }, parseInt)},
+	{"GODB_SHUTDOWN_TIMEOUT", "shutdown-timeout", "how long RPCs may run after a stop signal", field(func(c *Config) *Duration { return &c.Limits.ShutdownTimeout }, parseDuration)},
+	{"GODB_TLS_CERT_FILE", "tls-cert", "TLS certificate file", field(func(c *Config) *string { return &c.TLS.CertFile }, parseString)},
+	{"GODB_TLS_KEY_FILE", "tls-key", "TLS private key file", field(func(c *Config) *string { return &c.TLS.KeyFile }, parseString)},
+	{"GODB_TLS_CLIENT_CA_FILE", "tls-client-ca", "CA certificate clients must be signed by", field(func(c *Config) *string { return &c.TLS.ClientCAFile }, parseString)},
+	{"GODB_AUDIT_DIR", "", "", field(func(c *Config) *string { return &c.Logging.Audit.Dir }, parseString)},
+	{"GODB_AUDIT_KEY_FILE", "", "", field(func(c *Config) *string { return &c.Logging.Audit.KeyFile }, parseString)},
+	{"GODB_AUDIT_PUBLIC_KEY_FILE", "audit-public-key", "public key -verify-audit-log checks checkpoints against", field(func(c *Config) *string { return &c.Logging.Audit.PublicKeyFile }, parseString)},
+	{"GODB_AUDIT_MAX_SIZE", "", "", field(func(c *Config) *int64 { return &c.Logging.Audit.MaxSize }, parseInt)},
+	{"GODB_AUDIT_MAX_AGE", "", "", field(func(c *Config) *Duration { return &c.Logging.Audit.MaxAge }, parseDuration)},
+	{"GODB_AUDIT_RETENTION", "", "", field(func(c *Config) *Duration { return &c.Logging.Audit.Retention }, parseDuration)},
+	{"GODB_AUDIT_COMPRESS", "", "", field(func(c *Config) *bool { return &c.Logging.Audit.Compress }, strconv.ParseBool)},
+	{"GODB_AUDIT_SENSITIVE_COLUMNS", "", "", field(func(c *Config) *[]string { return &c.Logging.Audit.SensitiveColumns }, parseList)},
+	{"GODB_SLOW_QUERY_LOG", "", "", field(func(c *Config) *string { return &c.Logging.SlowQuery.Log }, parseString)},
+	{"GODB_SLOW_QUERY_THRESHOLD", "", "", field(func(c *Config) *string { return &c.Logging.SlowQuery.Threshold }, parseString)},
+	{"GODB_METRICS_ADDR", "metrics-addr", `Prometheus metrics address, or "off"`, setMetricsAddr},
+	{"GODB_TRACE_EXPORTER", "", "", field(func(c *Config) *string { return &c.Features.Tracing.Exporter }, parseString)},
+	{"GODB_TRACE_FILE", "", "", field(func(c *Config) *string { return &c.Features.Tracing.File }, parseString)},
+	{"GODB_OTLP_ENDPOINT", "", "", field(func(c *Config) *string { return &c.Features.Tracing.OTLPEndpoint }, parseString)},
+	{"GODB_REFLECTION", "reflection", "enable gRPC server reflection", field(func(c *Config) *bool { return &c.Features.Reflection }, strconv.ParseBool)},
+}
+
+// field returns a setter parsing a value into the field get points to.
+func field[T any](get func(*Config) *T, parse func(string) (T, error)) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		v, err := parse(value)
+		if err != nil {
+			return err
+		}
+		*get(c) = v
+		return nil
+	}
+}
+
+func parseString(value string) (string, error) { return value, nil }
+
+func parseInt(value string) (int64, error) { return strconv.ParseInt(value, 10, 64) }
+
+func parseDuration(value string) (Duration, error) {
+	d, err := time.ParseDuration(value)
+	return Duration(d), err
+}
+
+// parseList splits a comma-separated list, dropping empty items.
+func parseList(value string) ([]string, error) {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list, nil
+}
+
+// setMetricsAddr accepts an address, or "off" to disable the endpoint.
+func setMetricsAddr(c *Config, value string) error {
+	if value == "off" {
+		c.Features.Metrics.Enabled = false
+		return nil
+	}
+	c.Features.Metrics.Enabled = true
+	c.Features.Metrics.Addr = value
+	return nil
+}
+
+// applyEnv applies the environment variables that are set, reporting every
+// invalid one.
+func (c *Config) applyEnv() error {
+	var errs []error
+	for _, s := range settings {
+		value, ok := os.LookupEnv(s.env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := s.set(c, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", s.env, value, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Flags are the command-line flags registered by RegisterFlags.
+type Flags struct {
+	path   string
+	values map[string]*flagValue
+}
+
+// flagValue remembers whether a flag was given, so that flags left unset do
+// not override the file or the environment.
+type flagValue struct {
+	value   string
+	set     bool
+	boolean bool
+}
+
+func (v *flagValue) String() string     { return v.value }
+func (v *flagValue) IsBoolFlag() bool   { return v.boolean }
+func (v *flagValue) Set(s string) error { v.value, v.set = s, true; return nil }
+
+// boolFlags may be given without a value, as in -reflection.
+var boolFlags = map[string]bool{"reflection": true}
+
+// RegisterFlags adds -config and the flag of every setting that has one to
+// fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{values: map[string]*flagValue{}}
+	fs.StringVar(&f.path, "config", "", "configuration file (.yaml, .toml or .json); defaults to $GODB_CONFIG")
+	for _, s := range settings {
+		if s.flag != "" {
+			f.values[s.flag] = &flagValue{boolean: boolFlags[s.flag]}
+			fs.Var(f.values[s.flag], s.flag, s.usage+" ($"+s.env+")")
+		}
+	}
+	return f
+}
+
+func (f *Flags) apply(c *Config) error {
+	if f == nil {
+		return nil
+	}
+	var errs []error
+	for _, s := range settings {
+		v := f.values[s.flag]
+		if s.flag == "" || !v.set {
+			continue
+		}
+		if err := s.set(c, v.value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid -%s %q: %w", s.flag, v.value, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+)
+
+// deniedPragmas can corrupt the database file or escape the data directory.
+var deniedPragmas = map[string]bool{
+	"writable_schema":          true,
+	"schema_version":           true,
+	"trusted_schema":           true,
+	"cell_size_check":          true,
+	"temp_store_directory":     true,
+	"data_store_directory":     true,
+	"legacy_alter_table":       true,
+	"ignore_check_constraints": true,
+}
+
+// deniedFunctions are SQL functions that reach outside the database.
+var deniedFunctions = map[string]bool{
+	"load_extension": true,
+	"fts3_tokenizer": true,
+}
+
+// RestrictedConn returns a dedicated connection on which the SQLite
+// authorizer refuses ATTACH/DETACH, dangerous pragmas, extension loading and
+// writes to the server's bookkeeping tables. The caller must close it; the
+// authorizer is removed before the connection goes back to the pool.
+func RestrictedConn(ctx context.Context, database *sql.DB) (*RestrictedConnection, error) {
+	conn, err := database.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := setAuthorizer(conn, authorize); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &RestrictedConnection{Conn: conn}, nil
+}
+
+// Vet compiles stmt on a restricted connection without running it and
+// returns the authorizer's refusal, if any. SQLite authorizes a statement
+// when it is compiled, so a vetted statement can then be prepared on any
+// connection of the handle, as prepared statements are.
+func Vet(ctx context.Context, database *sql.DB, stmt string) error {
+	conn, err := RestrictedConn(ctx, database)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	compiled, err := conn.PrepareContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+	return compiled.Close()
+}
+
+// RestrictedConnection is a connection with the restrictive authorizer installed.
+type RestrictedConnection struct {
+	*sql.Conn
+}
+
+// Close removes the authorizer and releases the connection.
+func (c *RestrictedConnection) Close() error {
+	err := setAuthorizer(c.Conn, nil)
+	if closeErr := c.Conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Script runs fn with BEGIN, COMMIT and ROLLBACK refused as well. Scripts
+// run inside a transaction the caller opened on the connection, which they
+// must not end early.
+func (c *RestrictedConnection) Script(fn func() error) error {
+	return c.withAuthorizer(authorizeScript, fn)
+}
+
+// unrestricted runs fn with the authorizer removed, for the server's own
+// statements on the connection.
+func (c *RestrictedConnection) unrestricted(fn func() error) error {
+	return c.withAuthorizer(nil, fn)
+}
+
+// withAuthorizer runs fn under callback, then reinstalls authorize.
+// Statements are authorized when prepared, so fn must prepare the ones it
+// runs itself.
+func (c *RestrictedConnection) withAuthorizer(callback func(int, string, string, string) int, fn func() error) error {
+	if err := setAuthorizer(c.Conn, callback); err != nil {
+		return err
+	}
+	err := fn()
+	if restoreErr := setAuthorizer(c.Conn, authorize); err == nil {
+		err = restoreErr
+	}
+	return err
+}
+
+func setAuthorizer(conn *sql.Conn, callback func(int, string, string, string) int) error {
+	return conn.Raw(func(driverConn interface{}) error {
+		c, ok := unwrapConn(driverConn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		c.RegisterAuthorizer(callback)
+		return nil
+	})
+}
+
+// authorize is the SQLite authorizer callback. For most actions arg1 is the
+// affected table; for pragmas it is the pragma name and for functions arg2 is
+// the function name.
+func authorize(action int, arg1, arg2, arg3 string) int {
+	switch action {
+	case sqlite3.SQLITE_ATTACH, sqlite3.SQLITE_DETACH:
+		return sqlite3.SQLITE_DENY
+	case sqlite3.SQLITE_PRAGMA:
+		if deniedPragmas[strings.ToLower(arg1)] {
+			return sqlite3.SQLITE_DENY
+		}
+	case sqlite3.SQLITE_FUNCTION:
+		if deniedFunctions[strings.ToLower(arg2)] {
+			return sqlite3.SQLITE_DENY
+		}
+	case sqlite3.SQLITE_INSERT, sqlite3.SQLITE_UPDATE, sqlite3.SQLITE_DELETE, sqlite3.SQLITE_DROP_TABLE:
+		if internalTables[strings.ToLower(arg1)] {
+			return sqlite3.SQLITE_DENY
+		}
+	case sqlite3.SQLITE_ALTER_TABLE, sqlite3.SQLITE_CREATE_TRIGGER, sqlite3.SQLITE_CREATE_TEMP_TRIGGER:
+		// ALTER TABLE reports the database as arg1 and the table as arg2;
+		// triggers report the table they are attached to as arg2.
+		if internalTables[strings.ToLower(arg2)] {
+			return sqlite3.SQLITE_DENY
+		}
+	}
+	return sqlite3.SQLITE_OK
+}
+
+// authorizeScript is authorize for statements of a script, which may not
+// begin, commit or roll back transactions.
+func authorizeScript(action int, arg1, arg2, arg3 string) int {
+	if action == sqlite3.SQLITE_TRANSACTION {
+		return sqlite3.SQLITE_DENY
+	}
+	return authorize(action, arg1, arg2, arg3)
+}
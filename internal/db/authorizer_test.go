@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// deniedStatements are refused on a restricted connection, alone or in a script.
+var deniedStatements = []string{
+	"ATTACH DATABASE 'other.db' AS other",
+	"DETACH DATABASE other",
+	"PRAGMA writable_schema = ON",
+	"PRAGMA writable_schema",
+	"SELECT load_extension('libevil')",
+	"SELECT fts3_tokenizer('simple')",
+	"INSERT INTO indexes (table_name, index_name, columns) VALUES ('t', 'i', 'a')",
+	"UPDATE indexes SET columns = 'b'",
+	"DELETE FROM indexes",
+	"INSERT INTO schema_migrations (scope, version, name, checksum) VALUES ('client', 99, 'x', 'x')",
+	"UPDATE schema_migrations SET checksum = 'x'",
+	"DELETE FROM schema_migrations",
+	"DROP TABLE schema_migrations",
+	"ALTER TABLE indexes ADD COLUMN x TEXT",
+	"CREATE TRIGGER evil AFTER INSERT ON indexes BEGIN SELECT 1; END",
+}
+
+func openRestricted(t *testing.T) (*sql.DB, *RestrictedConnection) {
+	t.Helper()
+	database, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	if err := RunMigrations(database); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec("CREATE TABLE t (a TEXT)"); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := RestrictedConn(context.Background(), database)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return database, conn
+}
+
+func requireDenied(t *testing.T, stmt string, err error) {
+	t.Helper()
+	if err == nil || !strings.Contains(err.Error(), "authoriz") {
+		t.Errorf("%s: got %v, want an authorization error", stmt, err)
+	}
+}
+
+func TestRestrictedConnDeniesStatements(t *testing.T) {
+	ctx := context.Background()
+	_, conn := openRestricted(t)
+	for _, stmt := range deniedStatements {
+		_, err := conn.ExecContext(ctx, stmt)
+		requireDenied(t, stmt, err)
+	}
+
+	// VACUUM INTO attaches its target, and cannot run in a script's
+	// transaction at all.
+	_, err := conn.ExecContext(ctx, "VACUUM INTO 'copy.db'")
+	requireDenied(t, "VACUUM INTO", err)
+
+	// Ordinary statements still run.
+	for _, stmt := range []string{"INSERT INTO t (a) VALUES ('x')", "SELECT * FROM indexes", "PRAGMA table_info(t)", "CREATE INDEX t_a ON t (a)"} {
+		if _, err := conn.ExecContext(ctx, stmt); err != nil {
+			t.Errorf("%s: %v", stmt, err)
+		}
+	}
+}
+
+func TestRestrictedConnDeniesScriptStatements(t *testing.T) {
+	ctx := context.Background()
+	_, conn := openRestricted(t)
+	script := append([]string{"BEGIN", "COMMIT", "ROLLBACK", "END"}, deniedStatements...)
+	for _, stmt := range script {
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = conn.Script(func() error {
+			if _, err := tx.ExecContext(ctx, "INSERT INTO t (a) VALUES ('script')"); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, stmt)
+			return err
+		})
+		requireDenied(t, stmt, err)
+		if err := tx.Rollback(); err != nil {
+			t.Fatalf("%s: rollback: %v", stmt, err)
+		}
+	}
+
+	var n int
+	if err := conn.QueryRowContext(ctx, "SELECT COUNT(*) FROM t WHERE a = 'script'").Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	if n != 0 {
+		t.Errorf("%d rows of rolled back scripts were kept", n)
+	}
+
+	// Outside a script, the connection may run transactions again.
+	if _, err := conn.ExecContext(ctx, "BEGIN"); err != nil {
+		t.Fatalf("BEGIN after script: %v", err)
+	}
+	if _, err := conn.ExecContext(ctx, "COMMIT"); err != nil {
+		t.Fatalf("COMMIT after script: %v", err)
+	}
+}
+
+func TestRestrictedConnDeniesTriggerBodies(t *testing.T) {
+	ctx := context.Background()
+	database, conn := openRestricted(t)
+	// SQLite authorizes trigger bodies when the statement firing them is
+	// compiled, not when the trigger is created.
+	if _, err := database.Exec("CREATE TRIGGER sneaky AFTER INSERT ON t BEGIN DELETE FROM indexes; END"); err != nil {
+		t.Fatal(err)
+	}
+	_, err := conn.ExecContext(ctx, "INSERT INTO t (a) VALUES ('x')")
+	requireDenied(t, "INSERT firing a trigger", err)
+}
+
+func TestVet(t *testing.T) {
+	ctx := context.Background()
+	database, conn := openRestricted(t)
+	conn.Close()
+	if err := Vet(ctx, database, "SELECT * FROM t WHERE a = ?"); err != nil {
+		t.Errorf("Vet of a plain SELECT: %v", err)
+	}
+	err := Vet(ctx, database, "SELECT * FROM t WHERE load_extension('libevil') IS NULL")
+	requireDenied(t, "Vet of load_extension", err)
+}
+
+func TestRestrictedConnCloseRemovesAuthorizer(t *testing.T) {
+	ctx := context.Background()
+	database, conn := openRestricted(t)
+	database.SetMaxOpenConns(1)
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// The single pooled connection is the one that was restricted.
+	if _, err := database.ExecContext(ctx, "DELETE FROM indexes"); err != nil {
+		t.Errorf("authorizer still installed after Close: %v", err)
+	}
+}
@@ -0,0 +1,434 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// Index origins as reported by PRAGMA index_list.
+const (
+	IndexOriginCreate     = "c"
+	IndexOriginUnique     = "u"
+	IndexOriginPrimaryKey = "pk"
+)
+
+// internalTables are bookkeeping tables owned by the server. They are hidden
+// from catalog listings.
+var internalTables = map[string]bool{
+	"indexes":           true,
+	"schema_migrations": true,
+}
+
+// IsInternalTable reports whether a table is server bookkeeping or belongs to SQLite itself.
+func IsInternalTable(name string) bool {
+	lower := strings.ToLower(name)
+	return internalTables[lower] || strings.HasPrefix(lower, "sqlite_")
+}
+
+// Execer is satisfied by both *sql.DB and *sql.Tx.
+type Execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// IndexInfo describes an index as recorded in SQLite's own catalog.
+type IndexInfo struct {
+	Name  string
+	Table string
+	// Columns holds column names, or the expression text for expression terms.
+	Columns []string
+	Unique  bool
+	Partial bool
+	// Where is the partial index predicate, if any.
+	Where  string
+	Origin string
+	// SQL is the CREATE INDEX statement; empty for constraint-created indexes.
+	SQL string
+}
+
+// TableExists reports whether a table with the given name exists.
+func TableExists(db *sql.DB, table string) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", table).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// UserTables returns the names of all tables that are not internal. Search
+// indexes and their FTS5 shadow tables are not user tables either.
+func UserTables(db *sql.DB) ([]string, error) {
+	rows, err := db.Query("SELECT name, sql FROM sqlite_master WHERE type = 'table' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	virtual := map[string]bool{}
+	for rows.Next() {
+		var name string
+		var createSQL sql.NullString
+		if err := rows.Scan(&name, &createSQL); err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(strings.ToUpper(createSQL.String), "CREATE VIRTUAL TABLE") {
+			virtual[strings.ToLower(name)] = true
+			continue
+		}
+		names = append(names, name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var tables []string
+	for _, name := range names {
+		if !IsInternalTable(name) && !isSearchShadowTable(name, virtual) {
+			tables = append(tables, name)
+		}
+	}
+	return tables, nil
+}
+
+// ListIndexes reads index definitions from sqlite_master and PRAGMA
+// index_list/index_xinfo. When table is empty, indexes on all user tables
+// are returned.
+func ListIndexes(db *sql.DB, table string) ([]IndexInfo, error) {
+	tables := []string{table}
+	if table == "" {
+		var err error
+		tables, err = UserTables(db)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var indexes []IndexInfo
+	for _, t := range tables {
+		tableIndexes, err := tableIndexes(db, t)
+		if err != nil {
+			return nil, err
+		}
+		indexes = append(indexes, tableIndexes...)
+	}
+	return indexes, nil
+}
+
+func tableIndexes(db *sql.DB, table string) ([]IndexInfo, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA index_list(%s)", QuoteIdentifier(table)))
+	if err != nil {
+		return nil, err
+	}
+	var indexes []IndexInfo
+	for rows.Next() {
+		var seq, unique, partial int
+		var info IndexInfo
+		if err := rows.Scan(&seq, &info.Name, &unique, &info.Origin, &partial); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		info.Table = table
+		info.Unique = unique == 1
+		info.Partial = partial == 1
+		indexes = append(indexes, info)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range indexes {
+		if err := describeIndex(db, &indexes[i]); err != nil {
+			return nil, err
+		}
+	}
+	return indexes, nil
+}
+
+// describeIndex fills in the columns, SQL text and partial predicate of an index.
+func describeIndex(db *sql.DB, info *IndexInfo) error {
+	var createSQL sql.NullString
+	err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'index' AND name = ?", info.Name).Scan(&createSQL)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	info.SQL = createSQL.String
+	terms, where := splitIndexSQL(info.SQL)
+	if info.Partial {
+		info.Where = where
+	}
+
+	rows, err := db.Query(fmt.Sprintf("PRAGMA index_xinfo(%s)", QuoteIdentifier(info.Name)))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var seqno, cid, desc, key int
+		var name, coll sql.NullString
+		if err := rows.Scan(&seqno, &cid, &name, &desc, &coll, &key); err != nil {
+			return err
+		}
+		if key == 0 {
+			continue
+		}
+		switch {
+		case name.Valid:
+			info.Columns = append(info.Columns, name.String)
+		case seqno < len(terms):
+			info.Columns = append(info.Columns, terms[seqno])
+		default:
+			info.Columns = append(info.Columns, "<expression>")
+		}
+	}
+	return rows.Err()
+}
+
+// splitIndexSQL extracts the indexed terms and the WHERE predicate from a
+// CREATE INDEX statement.
+func splitIndexSQL(stmt string) (terms []string, where string) {
+	open := strings.Index(stmt, "(")
+	if open < 0 {
+		return nil, ""
+	}
+	depth := 0
+	start := open + 1
+	inString := byte(0)
+	for i := open; i < len(stmt); i++ {
+		c := stmt[i]
+		if inString != 0 {
+			if c == inString {
+				inString = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"', '`':
+			inString = c
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				terms = append(terms, strings.TrimSpace(stmt[start:i]))
+				rest := strings.TrimSpace(stmt[i+1:])
+				if len(rest) > 6 && strings.EqualFold(rest[:6], "WHERE ") {
+					where = strings.TrimSpace(rest[6:])
+				}
+				return terms, where
+			}
+		case ',':
+			if depth == 1 {
+				terms = append(terms, strings.TrimSpace(stmt[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return terms, ""
+}
+
+// QuoteIdentifier quotes an SQL identifier for SQLite.
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// LegacyIndexTable is the bookkeeping table AddIndex has historically
+// written to. SQLite's catalog is the source of truth; this table is only
+// kept in sync for older clients that read it directly.
+const LegacyIndexTable = "indexes"
+
+// RecordIndexMetadata stores an index in the legacy bookkeeping table, if it
+// still exists.
+func RecordIndexMetadata(exec Execer, table, index, columns string) error {
+	var count int
+	err := exec.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", LegacyIndexTable).Scan(&count)
+	if err != nil || count == 0 {
+		return err
+	}
+	_, err = exec.Exec("INSERT OR REPLACE INTO indexes (table_name, index_name, columns) VALUES (?, ?, ?)", table, index, columns)
+	return err
+}
+
+// ReconcileIndexMetadata rewrites the legacy bookkeeping table from SQLite's
+// catalog: rows for indexes that no longer exist are removed and indexes that
+// were never recorded are added. Constraint-created indexes are included.
+func ReconcileIndexMetadata(db *sql.DB) (added, removed int, err error) {
+	exists, err := TableExists(db, LegacyIndexTable)
+	if err != nil || !exists {
+		return 0, 0, err
+	}
+
+	indexes, err := ListIndexes(db, "")
+	if err != nil {
+		return 0, 0, err
+	}
+	actual := map[string]IndexInfo{}
+	for _, idx := range indexes {
+		actual[idx.Name] = idx
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT index_name FROM indexes")
+	if err != nil {
+		return 0, 0, err
+	}
+	recorded := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		recorded[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for name := range recorded {
+		if _, ok := actual[name]; ok {
+			continue
+		}
+		if _, err := tx.Exec("DELETE FROM indexes WHERE index_name = ?", name); err != nil {
+			return 0, 0, err
+		}
+		removed++
+	}
+	for name, idx := range actual {
+		if recorded[name] {
+			continue
+		}
+		_, err := tx.Exec("INSERT INTO indexes (table_name, index_name, columns) VALUES (?, ?, ?)",
+			idx.Table, idx.Name, strings.Join(idx.Columns, ", "))
+		if err != nil {
+			return 0, 0, err
+		}
+		added++
+	}
+
+	return added, removed, tx.Commit()
+}
+
+// DropIndexMetadata removes the legacy bookkeeping table entirely.
+func DropIndexMetadata(db *sql.DB) (bool, error) {
+	exists, err := TableExists(db, LegacyIndexTable)
+	if err != nil || !exists {
+		return false, err
+	}
+	if _, err := db.Exec("DROP TABLE indexes"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ColumnInfo describes a table column as reported by PRAGMA table_info.
+type ColumnInfo struct {
+	Name       string
+	Type       string
+	NotNull    bool
+	PrimaryKey bool
+}
+
+// TableColumns returns the columns of a table in declaration order.
+func TableColumns(db Execer, table string) ([]ColumnInfo, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", QuoteIdentifier(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []ColumnInfo
+	for rows.Next() {
+		var cid, notNull, pk int
+		var col ColumnInfo
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &col.Name, &col.Type, &notNull, &dflt, &pk); err != nil {
+			return nil, err
+		}
+		col.NotNull = notNull == 1
+		col.PrimaryKey = pk > 0
+		columns = append(columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("table '%s' not found", table)
+	}
+	return columns, nil
+}
+
+// ForeignKey is a foreign key constraint declared on a table.
+type ForeignKey struct {
+	// Table is the referenced (parent) table.
+	Table string
+	// From are the child columns and To the matching parent columns.
+	From []string
+	To   []string
+}
+
+// ForeignKeys returns the foreign keys declared on a table, as reported by
+// PRAGMA foreign_key_list. References that omit the parent columns are
+// resolved to the parent's primary key.
+func ForeignKeys(db Execer, table string) ([]ForeignKey, error) {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA foreign_key_list(%s)", QuoteIdentifier(table)))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []ForeignKey
+	byID := map[int]int{}
+	for rows.Next() {
+		var id, seq int
+		var parent, from, onUpdate, onDelete, match string
+		var to sql.NullString
+		if err := rows.Scan(&id, &seq, &parent, &from, &to, &onUpdate, &onDelete, &match); err != nil {
+			return nil, err
+		}
+		i, ok := byID[id]
+		if !ok {
+			i = len(keys)
+			byID[id] = i
+			keys = append(keys, ForeignKey{Table: parent})
+		}
+		keys[i].From = append(keys[i].From, from)
+		keys[i].To = append(keys[i].To, to.String)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	for i := range keys {
+		if keys[i].To[0] != "" {
+			continue
+		}
+		columns, err := TableColumns(db, keys[i].Table)
+		if err != nil {
+			return nil, err
+		}
+		var pk []string
+		for _, c := range columns {
+			if c.PrimaryKey {
+				pk = append(pk, c.Name)
+			}
+		}
+		if len(pk) != len(keys[i].From) {
+			return nil, fmt.Errorf("foreign key on '%s' does not match the primary key of '%s'", table, keys[i].Table)
+		}
+		keys[i].To = pk
+	}
+	return keys, nil
+}
@@ -1,31 +1,176 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/auth"
 )
 
-const DBDir = "data"
+// DBDir holds a directory per user with their databases. It is set from the
+// configuration before the server starts.
+var DBDir = "data"
+
+var (
+	// ErrInvalidDatabaseName is returned when a database name fails validation.
+	ErrInvalidDatabaseName = errors.New("invalid database name")
+	// ErrPathOutsideDataDir is returned when a resolved database path escapes DBDir.
+	ErrPathOutsideDataDir = errors.New("database path escapes the data directory")
+	// ErrDatabaseNotFound is returned by OpenDatabase when the database file
+	// does not exist and implicit creation is disabled.
+	ErrDatabaseNotFound = errors.New("database does not exist")
+)
+
+// RequireExistingDatabase controls whether OpenDatabase refuses to open a
+// database file that was not created through CreateDatabase first.
+var RequireExistingDatabase = true
+
+// databaseNamePattern allows letters, digits, underscores and hyphens only,
+// starting with a letter or digit, up to 64 characters.
+var databaseNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// ValidateDatabaseName checks that a database name is safe to use as a file name.
+func ValidateDatabaseName(name string) error {
+	if !databaseNamePattern.MatchString(name) {
+		return fmt.Errorf("%w: %q", ErrInvalidDatabaseName, name)
+	}
+	return nil
+}
+
+// GetDatabasePath returns the full path for a given database name.
+// Both the user ID and database name are validated and the resulting path
+// is checked to stay inside the user's directory under DBDir.
+func GetDatabasePath(userID, dbName string) (string, error) {
+	if err := auth.ValidateUsername(userID); err != nil {
+		return "", err
+	}
+	if err := ValidateDatabaseName(dbName); err != nil {
+		return "", err
+	}
 
-// GetDatabasePath returns the full path for a given database name
-func GetDatabasePath(userID, dbName string) string {
-	// The data directory is defined in the config package (or use a constant).
-	return filepath.Join(DBDir, userID, fmt.Sprintf("%s.db", dbName))
+	userDir, err := userDirectory(userID)
+	if err != nil {
+		return "", err
+	}
+	dbPath := filepath.Join(userDir, fmt.Sprintf("%s.db", dbName))
+	if err := ensureWithin(userDir, dbPath); err != nil {
+		return "", err
+	}
+	return dbPath, nil
 }
 
-// EnsureDBDirectory makes sure the `data/` directory exists
+// EnsureDBDirectory makes sure the data directory exists
 func EnsureDBDirectory() error {
 	if _, err := os.Stat(DBDir); os.IsNotExist(err) {
-		return os.Mkdir(DBDir, os.ModePerm)
+		return os.MkdirAll(DBDir, os.ModePerm)
 	}
 	return nil
 }
 
+// CheckDBDirectory reports whether the data directory exists and new files
+// can be created in it.
+func CheckDBDirectory(context.Context) error {
+	info, err := os.Stat(DBDir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", DBDir)
+	}
+	probe, err := os.CreateTemp(DBDir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("data directory is not writable: %w", err)
+	}
+	probe.Close()
+	return os.Remove(probe.Name())
+}
+
 func EnsureUserDBDirectory(userID string) error {
-	userDir := filepath.Join(DBDir, userID)
+	userDir, err := userDirectory(userID)
+	if err != nil {
+		return err
+	}
 	if _, err := os.Stat(userDir); os.IsNotExist(err) {
 		return os.Mkdir(userDir, os.ModePerm)
 	}
 	return nil
 }
+
+// userDirectory returns the validated directory holding a user's databases.
+func userDirectory(userID string) (string, error) {
+	if err := auth.ValidateUsername(userID); err != nil {
+		return "", err
+	}
+	userDir := filepath.Join(DBDir, userID)
+	if err := ensureWithin(DBDir, userDir); err != nil {
+		return "", err
+	}
+	return userDir, nil
+}
+
+// ensureWithin verifies that path resolves to a location strictly inside base,
+// following symlinks for any part of the path that already exists.
+func ensureWithin(base, path string) error {
+	baseAbs, err := canonicalPath(base)
+	if err != nil {
+		return err
+	}
+	pathAbs, err := canonicalPath(path)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(baseAbs, pathAbs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) || filepath.IsAbs(rel) {
+		return fmt.Errorf("%w: %s", ErrPathOutsideDataDir, path)
+	}
+	return nil
+}
+
+// canonicalPath returns the absolute path with symlinks resolved for the
+// longest existing prefix; missing trailing elements are appended unchanged.
+// A dangling symlink is followed to its target, which opening the path
+// would create.
+func canonicalPath(path string) (string, error) {
+	return canonicalPathDepth(path, 0)
+}
+
+// maxSymlinkHops bounds the dangling symlinks canonicalPath follows.
+const maxSymlinkHops = 40
+
+func canonicalPathDepth(path string, hops int) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	current := abs
+	for {
+		resolved, err := filepath.EvalSymlinks(current)
+		if err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		if target, err := os.Readlink(current); err == nil {
+			if hops >= maxSymlinkHops {
+				return "", fmt.Errorf("too many levels of symbolic links: %s", path)
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(filepath.Dir(current), target)
+			}
+			return canonicalPathDepth(filepath.Join(append([]string{target}, missing...)...), hops+1)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return abs, nil
+		}
+		missing = append([]string{filepath.Base(current)}, missing...)
+		current = parent
+	}
+}
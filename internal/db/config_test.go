@@ -0,0 +1,193 @@
+package db
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateDatabaseName(t *testing.T) {
+	tests := []struct {
+		name string
+		ok   bool
+	}{
+		{"shop", true},
+		{"Shop_2024", true},
+		{"a-b", true},
+		{strings.Repeat("d", 64), true},
+		{strings.Repeat("d", 65), false},
+		{"", false},
+		{"..", false},
+		{"../other/secret", false},
+		{"a/b", false},
+		{`a\b`, false},
+		{"/etc/passwd", false},
+		{"shop.db", false},
+		{".hidden", false},
+		{"_x", false},
+		{"a b", false},
+		{"a\x00", false},
+		{"<dbname>", false},
+	}
+	for _, tt := range tests {
+		err := ValidateDatabaseName(tt.name)
+		if tt.ok && err != nil {
+			t.Errorf("ValidateDatabaseName(%q) = %v, want nil", tt.name, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrInvalidDatabaseName) {
+			t.Errorf("ValidateDatabaseName(%q) = %v, want ErrInvalidDatabaseName", tt.name, err)
+		}
+	}
+}
+
+// withDBDir points DBDir at a fresh directory for the test and returns it.
+func withDBDir(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "data")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	old := DBDir
+	DBDir = dir
+	t.Cleanup(func() { DBDir = old })
+	return dir
+}
+
+func TestEnsureWithin(t *testing.T) {
+	base := withDBDir(t)
+	outside := t.TempDir()
+	if err := os.Mkdir(filepath.Join(base, "alice"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(base, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(base, "alice"), filepath.Join(base, "alias")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		ok   bool
+	}{
+		{filepath.Join(base, "alice"), true},
+		{filepath.Join(base, "alice", "shop.db"), true},
+		{filepath.Join(base, "bob", "new", "shop.db"), true},
+		{filepath.Join(base, "alias", "shop.db"), true},
+		{base, false},
+		{filepath.Join(base, ".."), false},
+		{filepath.Join(base, "..", "data2", "x.db"), false},
+		{base + "/alice/../../x.db", false},
+		{outside, false},
+		{"/etc/passwd", false},
+		{filepath.Join(base, "escape"), false},
+		{filepath.Join(base, "escape", "shop.db"), false},
+	}
+	for _, tt := range tests {
+		err := ensureWithin(base, tt.path)
+		if tt.ok && err != nil {
+			t.Errorf("ensureWithin(%q) = %v, want nil", tt.path, err)
+		}
+		if !tt.ok && !errors.Is(err, ErrPathOutsideDataDir) {
+			t.Errorf("ensureWithin(%q) = %v, want ErrPathOutsideDataDir", tt.path, err)
+		}
+	}
+}
+
+func TestCanonicalPath(t *testing.T) {
+	base := withDBDir(t)
+	resolved, err := filepath.EvalSymlinks(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := t.TempDir()
+	realTarget, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, filepath.Join(base, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(target, "gone.db"), filepath.Join(base, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct{ path, want string }{
+		{base, resolved},
+		{filepath.Join(base, "missing", "x.db"), filepath.Join(resolved, "missing", "x.db")},
+		{filepath.Join(base, "a", "..", "b"), filepath.Join(resolved, "b")},
+		{filepath.Join(base, "link"), realTarget},
+		{filepath.Join(base, "link", "missing.db"), filepath.Join(realTarget, "missing.db")},
+		{filepath.Join(base, "dangling"), filepath.Join(realTarget, "gone.db")},
+	}
+	for _, tt := range tests {
+		got, err := canonicalPath(tt.path)
+		if err != nil {
+			t.Errorf("canonicalPath(%q): %v", tt.path, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("canonicalPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	if got, err := canonicalPath("relative-missing"); err != nil || !filepath.IsAbs(got) || filepath.Base(got) != "relative-missing" {
+		t.Errorf("canonicalPath(%q) = %q, %v; want an absolute path", "relative-missing", got, err)
+	}
+}
+
+func TestGetDatabasePath(t *testing.T) {
+	base := withDBDir(t)
+	got, err := GetDatabasePath("alice", "shop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(base, "alice", "shop.db"); got != want {
+		t.Errorf("GetDatabasePath = %q, want %q", got, want)
+	}
+
+	for _, tt := range []struct{ user, database string }{
+		{"../bob", "shop"},
+		{"alice", "../bob/secret"},
+		{"alice", "/etc/passwd"},
+		{"", "shop"},
+		{"alice", ""},
+		{strings.Repeat("u", 65), "shop"},
+		{"alice", strings.Repeat("d", 65)},
+	} {
+		if _, err := GetDatabasePath(tt.user, tt.database); err == nil {
+			t.Errorf("GetDatabasePath(%q, %q) succeeded", tt.user, tt.database)
+		}
+	}
+
+	// A user directory that is a symlink out of DBDir is refused.
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(base, "mallory")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetDatabasePath("mallory", "shop"); !errors.Is(err, ErrPathOutsideDataDir) {
+		t.Errorf("GetDatabasePath through a symlinked user directory = %v, want ErrPathOutsideDataDir", err)
+	}
+	// So is a database file that is a symlink out of the user directory.
+	if err := os.Mkdir(filepath.Join(base, "eve"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "x.db"), filepath.Join(base, "eve", "shop.db")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "x.db"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetDatabasePath("eve", "shop"); !errors.Is(err, ErrPathOutsideDataDir) {
+		t.Errorf("GetDatabasePath of a symlinked database file = %v, want ErrPathOutsideDataDir", err)
+	}
+	// Even when the link dangles, since opening it would create the target.
+	if err := os.Symlink(filepath.Join(outside, "new.db"), filepath.Join(base, "eve", "fresh.db")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetDatabasePath("eve", "fresh"); !errors.Is(err, ErrPathOutsideDataDir) {
+		t.Errorf("GetDatabasePath of a dangling symlink = %v, want ErrPathOutsideDataDir", err)
+	}
+}
@@ -1,30 +1,93 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
-	"strings"
+	"os"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/prakhar-5447/GoDB/internal/auth"
+	"github.com/prakhar-5447/GoDB/internal/tracing"
 )
 
-func OpenDatabase(ConnectionString string) (*sql.DB, error) {
-	// Authenticate using the connection string from the request.
+// OpenDatabase authenticates the connection string and opens an existing
+// user database. When RequireExistingDatabase is set, a database that was
+// never created through CreateDatabase is reported as ErrDatabaseNotFound
+// instead of being created on the fly.
+func OpenDatabase(ctx context.Context, ConnectionString string) (*sql.DB, error) {
+	return openDatabase(ctx, ConnectionString, !RequireExistingDatabase)
+}
+
+// CreateDatabase authenticates the connection string and opens the user
+// database, creating the file if it does not exist yet.
+func CreateDatabase(ctx context.Context, ConnectionString string) (*sql.DB, error) {
+	return openDatabase(ctx, ConnectionString, true)
+}
+
+func openDatabase(ctx context.Context, ConnectionString string, create bool) (*sql.DB, error) {
+	info, err := Authenticate(ctx, ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	if create && info.ReadOnly() {
+		return nil, fmt.Errorf("cannot create database %s in read-only mode", info.Database)
+	}
+
+	// Log successful authentication.
+	log.Printf("Authenticated user %s for database %s", info.Username, info.Database)
+
+	return openUserDatabase(ctx, info.Username, info.Database, info.Options, create)
+}
+
+// Authenticate parses the connection string and validates its credentials
+// without opening the database.
+func Authenticate(ctx context.Context, ConnectionString string) (*ConnectionInfo, error) {
 	// Parse the connection string to extract user info.
-	username, password, dbName, err := ParseConnectionString(ConnectionString)
+	info, err := ParseConnection(ConnectionString)
 	if err != nil {
 		return nil, err
 	}
+
 	// Validate credentials using the auth package.
-	auth, err := auth.ValidateUserCredentials(username, password)
-	if !auth || err != nil {
+	_, span := tracing.Start(ctx, "auth.ValidateUserCredentials", tracing.KindInternal)
+	span.SetAttribute("enduser.id", info.Username)
+	ok, err := auth.ValidateUserCredentials(info.Username, info.Password)
+	span.SetError(err)
+	span.End()
+	if !ok || err != nil {
 		return nil, fmt.Errorf("authentication failed")
 	}
+	auth.MarkVerified(ctx, info.Username)
+	return info, nil
+}
 
-	// Log successful authentication.
-	log.Printf("Authenticated user %s for database %s", username, dbName)
+// OpenAuthenticated opens the existing database of a connection that
+// Authenticate has already accepted, honoring its options.
+func OpenAuthenticated(ctx context.Context, info *ConnectionInfo) (*sql.DB, error) {
+	return openUserDatabase(ctx, info.Username, info.Database, info.Options, false)
+}
+
+// OpenUserDatabase opens an existing database on behalf of the server itself,
+// without credentials. It is meant for background maintenance tasks; RPC
+// handlers must go through OpenDatabase.
+func OpenUserDatabase(username, dbName string) (*sql.DB, error) {
+	return openUserDatabase(context.Background(), username, dbName, ConnectionOptions{Mode: ModeReadWrite}, false)
+}
+
+func openUserDatabase(ctx context.Context, username, dbName string, options ConnectionOptions, create bool) (db *sql.DB, err error) {
+	// Statements on the handle belong to the caller's span; the open itself
+	// gets a span of its own.
+	parent := tracing.ParentFromContext(ctx)
+	ctx, span := tracing.Start(ctx, "db.OpenDatabase", tracing.KindInternal)
+	span.SetAttribute("enduser.id", username)
+	span.SetAttribute("db.name", dbName)
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	readOnly := options.Mode == ModeReadOnly
 
 	// Ensure the user's database directory exists.
 	if err := EnsureUserDBDirectory(username); err != nil {
@@ -32,48 +95,46 @@ func OpenDatabase(ConnectionString string) (*sql.DB, error) {
 	}
 
 	// Build the full database path using the user ID and database name.
-	dbPath := GetDatabasePath(username, dbName)
-
-	// Open a connection to the SQLite database.
-	db, err := sql.Open("sqlite3", dbPath)
+	dbPath, err := GetDatabasePath(username, dbName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open database %s: %w", dbName, err)
+		return nil, err
 	}
 
-	// Optionally enable foreign key constraints.
-	_, err = db.Exec("PRAGMA foreign_keys = ON;")
-	if err != nil {
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
+	// Refuse to create new files unless explicitly asked to. Read-only
+	// connections can never create a file.
+	if !create || readOnly {
+		if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrDatabaseNotFound, dbName)
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to stat database %s: %w", dbName, err)
+		}
 	}
 
-	return db, nil
-}
-
-// ParseConnectionString extracts the username, password, and database name.
-// Expected format: grpc://username:password/databaseName
-func ParseConnectionString(connStr string) (username, password, database string, err error) {
-	// Ensure the connection string starts with "grpc://"
-	if !strings.HasPrefix(connStr, "grpc://") {
-		return "", "", "", fmt.Errorf("connection string must start with 'grpc://'")
-	}
-	trimmed := strings.TrimPrefix(connStr, "grpc://")
+	// Open a connection to the SQLite database, applying the access mode,
+	// busy timeout and journal mode requested in the connection string.
+	db = sql.OpenDB(&instrumentedConnector{
+		dsn:      dataSourceName(dbPath, options),
+		parent:   parent,
+		username: username,
+		database: dbName,
+	})
+	trackHandle(db)
 
-	// Split into credentials and database parts using "/"
-	parts := strings.SplitN(trimmed, "/", 2)
-	if len(parts) != 2 {
-		return "", "", "", fmt.Errorf("invalid connection string format: missing database name")
+	// Optionally enable foreign key constraints.
+	_, err = db.ExecContext(ctx, "PRAGMA foreign_keys = ON;")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
 	}
 
-	// Parse credentials (expected format "username:password")
-	credParts := strings.SplitN(parts[0], ":", 2)
-	if len(credParts) != 2 {
-		return "", "", "", fmt.Errorf("invalid credentials format")
+	// Bring the internal schema up to date. Read-only handles cannot write,
+	// so they see whatever version the file is already at.
+	if !readOnly {
+		if err := RunMigrations(db); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to migrate database %s: %w", dbName, err)
+		}
 	}
-	username = credParts[0]
-	password = credParts[1]
 
-	// The remainder is the database name.
-	database = parts[1]
-
-	return username, password, database, nil
+	return db, nil
 }
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CountCacheTTL is how long row and distinct value counts taken by scanning
+// a table are reused. The counts only feed advisory reports, so slightly
+// stale values are fine, and scanning a large table on every report is not.
+var CountCacheTTL = 10 * time.Minute
+
+var countCache = struct {
+	sync.Mutex
+	entries map[string]cachedCount
+}{entries: map[string]cachedCount{}}
+
+type cachedCount struct {
+	value   int64
+	expires time.Time
+}
+
+// TableStats returns the sqlite_stat1 entries of a table, keyed by lower-case
+// index name, with "" for the entry of the table itself. The map is empty
+// when the database has not been analyzed.
+func TableStats(db *sql.DB, table string) (map[string][]int64, error) {
+	stats := map[string][]int64{}
+	var analyzed bool
+	if err := db.QueryRow("SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'sqlite_stat1'").Scan(&analyzed); err != nil || !analyzed {
+		return stats, err
+	}
+
+	rows, err := db.Query("SELECT idx, stat FROM sqlite_stat1 WHERE tbl = ? COLLATE NOCASE", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var idx sql.NullString
+		var stat string
+		if err := rows.Scan(&idx, &stat); err != nil {
+			return nil, err
+		}
+		stats[strings.ToLower(idx.String)] = parseStat(stat)
+	}
+	return stats, rows.Err()
+}
+
+// parseStat reads the leading integers of a sqlite_stat1 stat column.
+// Trailing keywords such as "unordered" or "sz=N" are ignored.
+func parseStat(stat string) []int64 {
+	var values []int64
+	for _, field := range strings.Fields(stat) {
+		n, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			break
+		}
+		values = append(values, n)
+	}
+	return values
+}
+
+// EstimatedRows returns the row count ANALYZE recorded for a table, taken
+// from the table's own sqlite_stat1 entry or from a full index. Partial
+// indexes only count the rows they cover. ok is false when the table has not
+// been analyzed.
+func EstimatedRows(db *sql.DB, table string) (rows int64, ok bool, err error) {
+	stats, err := TableStats(db, table)
+	if err != nil || len(stats) == 0 {
+		return 0, false, err
+	}
+	if s := stats[""]; len(s) > 0 {
+		return s[0], true, nil
+	}
+	indexes, err := tableIndexes(db, table)
+	if err != nil {
+		return 0, false, err
+	}
+	for _, idx := range indexes {
+		if s := stats[strings.ToLower(idx.Name)]; !idx.Partial && len(s) > 0 {
+			return s[0], true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// RowCount estimates the rows in a table from sqlite_stat1, counting them
+// when the table has not been analyzed. Counts are cached for CountCacheTTL
+// under key, which identifies the database.
+func RowCount(db *sql.DB, key, table string) (int64, error) {
+	if rows, ok, err := EstimatedRows(db, table); err != nil || ok {
+		return rows, err
+	}
+	return cachedScan(key+"\x00"+table, func() (int64, error) {
+		var count int64
+		err := db.QueryRow(fmt.Sprintf("SELECT COUNT(*) FROM %s", QuoteIdentifier(table))).Scan(&count)
+		return count, err
+	})
+}
+
+// DistinctCount estimates the number of distinct values in a column, at
+// least 1. An analyzed full index led by the column gives the estimate;
+// otherwise the values are counted and cached like RowCount.
+func DistinctCount(db *sql.DB, key, table, column string) (int64, error) {
+	stats, err := TableStats(db, table)
+	if err != nil {
+		return 0, err
+	}
+	if len(stats) > 0 {
+		indexes, err := tableIndexes(db, table)
+		if err != nil {
+			return 0, err
+		}
+		for _, idx := range indexes {
+			s := stats[strings.ToLower(idx.Name)]
+			if idx.Partial || len(idx.Columns) == 0 || !strings.EqualFold(idx.Columns[0], column) || len(s) < 2 || s[1] <= 0 {
+				continue
+			}
+			// s[1] is the average number of rows sharing a value.
+			return max(s[0]/s[1], 1), nil
+		}
+	}
+	distinct, err := cachedScan(key+"\x00"+table+"\x00"+column, func() (int64, error) {
+		var distinct int64
+		q := fmt.Sprintf("SELECT COUNT(DISTINCT %s) FROM %s", QuoteIdentifier(column), QuoteIdentifier(table))
+		err := db.QueryRow(q).Scan(&distinct)
+		return distinct, err
+	})
+	return max(distinct, 1), err
+}
+
+// cachedScan returns the cached value for key, running scan when there is
+// none or it has expired.
+func cachedScan(key string, scan func() (int64, error)) (int64, error) {
+	key = strings.ToLower(key)
+	now := time.Now()
+	countCache.Lock()
+	entry, ok := countCache.entries[key]
+	countCache.Unlock()
+	if ok && now.Before(entry.expires) {
+		return entry.value, nil
+	}
+
+	value, err := scan()
+	if err != nil {
+		return 0, err
+	}
+	countCache.Lock()
+	defer countCache.Unlock()
+	for k, e := range countCache.entries {
+		if !now.Before(e.expires) {
+			delete(countCache.entries, k)
+		}
+	}
+	countCache.entries[key] = cachedCount{value: value, expires: now.Add(CountCacheTTL)}
+	return value, nil
+}
@@ -0,0 +1,7 @@
+//go:build sqlite_fts5 || fts5
+
+package db
+
+// FTS5Enabled reports whether the SQLite driver was built with the FTS5
+// extension, which full-text search indexes require.
+const FTS5Enabled = true
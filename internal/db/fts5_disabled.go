@@ -0,0 +1,8 @@
+//go:build !sqlite_fts5 && !fts5
+
+package db
+
+// FTS5Enabled reports whether the SQLite driver was built with the FTS5
+// extension, which full-text search indexes require. Build with
+// -tags sqlite_fts5 to enable it.
+const FTS5Enabled = false
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/prakhar-5447/GoDB/internal/slowlog"
+	"github.com/prakhar-5447/GoDB/internal/tracing"
+)
+
+// maxStatementText bounds the statement text attached to spans and slow
+// query log entries.
+const maxStatementText = 1024
+
+// maxParamShapes bounds the parameter types kept for a slow statement.
+const maxParamShapes = 64
+
+// instrumentedConnector opens user database connections whose statements are
+// recorded as spans and timed for the slow query log. Statements run with a
+// context carrying a span are children of that span; others are children of
+// the span that opened the database, so handlers do not need to pass a
+// context to every query.
+type instrumentedConnector struct {
+	dsn      string
+	parent   tracing.SpanContext
+	username string
+	database string
+}
+
+func (c *instrumentedConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := sqliteDriver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	sqliteConn, ok := conn.(*sqlite3.SQLiteConn)
+	if !ok {
+		return conn, nil
+	}
+	return &instrumentedConn{SQLiteConn: sqliteConn, connector: c}, nil
+}
+
+func (c *instrumentedConnector) Driver() driver.Driver { return sqliteDriver }
+
+// instrumentedConn wraps a SQLite connection; every method it does not
+// override is the driver's own.
+type instrumentedConn struct {
+	*sqlite3.SQLiteConn
+	connector *instrumentedConnector
+}
+
+// unwrapConn returns the SQLite connection behind a driver connection.
+func unwrapConn(conn interface{}) (*sqlite3.SQLiteConn, bool) {
+	switch c := conn.(type) {
+	case *instrumentedConn:
+		return c.SQLiteConn, true
+	case *sqlite3.SQLiteConn:
+		return c, true
+	}
+	return nil, false
+}
+
+// execution is one run of a statement, from the driver call until its
+// result or rows are done with.
+type execution struct {
+	conn      *instrumentedConn
+	query     string
+	operation string
+	args      []driver.NamedValue
+	span      *tracing.Span
+	start     time.Time
+}
+
+// begin starts timing query and, when ctx or the connection belongs to a
+// trace, a span for it.
+func (c *instrumentedConn) begin(ctx context.Context, query string, args []driver.NamedValue) *execution {
+	e := &execution{conn: c, query: query, operation: "SQL", args: args, start: time.Now()}
+	if fields := strings.Fields(query); len(fields) > 0 {
+		e.operation = strings.ToUpper(fields[0])
+	}
+	parent := c.connector.parent
+	if p := tracing.ParentFromContext(ctx); p.IsValid() {
+		parent = p
+	}
+	if parent.IsValid() {
+		e.span = tracing.StartWithParent(parent, "sqlite "+e.operation, tracing.KindClient)
+		e.span.SetAttribute("db.system", "sqlite")
+		e.span.SetAttribute("db.operation", e.operation)
+		// Traces leave the server; like the slow query log, they only get
+		// the statement with its literals replaced.
+		e.span.SetAttribute("db.statement", truncateStatement(slowlog.Normalize(query)))
+	}
+	return e
+}
+
+// end finishes the span and logs the statement if it was slow.
+func (e *execution) end(err error) {
+	elapsed := time.Since(e.start)
+	e.span.SetError(err)
+	e.span.End()
+
+	threshold := slowlog.Threshold(e.operation)
+	if threshold <= 0 || elapsed < threshold {
+		return
+	}
+	entry := slowlog.Entry{
+		Time:       e.start.UTC(),
+		User:       e.conn.connector.username,
+		Database:   e.conn.connector.database,
+		Statement:  truncateStatement(slowlog.Normalize(e.query)),
+		DurationMS: float64(elapsed) / float64(time.Millisecond),
+		Plan:       e.conn.queryPlan(e.query, e.args),
+	}
+	for i, arg := range e.args {
+		if i == maxParamShapes {
+			entry.Params = append(entry.Params, fmt.Sprintf("+%d more", len(e.args)-i))
+			break
+		}
+		entry.Params = append(entry.Params, slowlog.ParamType(arg.Value))
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	slowlog.Record(entry)
+}
+
+func truncateStatement(query string) string {
+	if len(query) > maxStatementText {
+		return query[:maxStatementText] + "…"
+	}
+	return query
+}
+
+// queryPlan summarizes how SQLite runs query, one line per plan step. Only
+// the first statement of a script is explained, and preparing the EXPLAIN
+// never runs the statement itself.
+func (c *instrumentedConn) queryPlan(query string, args []driver.NamedValue) []string {
+	switch strings.ToUpper(strings.Fields(query + " SQL")[0]) {
+	case "SELECT", "WITH", "INSERT", "REPLACE", "UPDATE", "DELETE", "VALUES":
+	default:
+		return nil
+	}
+	stmt, err := c.SQLiteConn.PrepareContext(context.Background(), "EXPLAIN QUERY PLAN "+query)
+	if err != nil {
+		return nil
+	}
+	defer stmt.Close()
+	n := stmt.NumInput()
+	if n < 0 || n > len(args) {
+		return nil
+	}
+	rows, err := stmt.(driver.StmtQueryContext).QueryContext(context.Background(), args[:n])
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	// Rows are id, parent, notused and detail; nested steps are indented
+	// under their parent.
+	var plan []string
+	depth := map[int64]int{}
+	values := make([]driver.Value, len(rows.Columns()))
+	for len(values) >= 4 && rows.Next(values) == nil {
+		id, _ := values[0].(int64)
+		parent, _ := values[1].(int64)
+		detail, _ := values[3].(string)
+		depth[id] = depth[parent] + 1
+		plan = append(plan, strings.Repeat("  ", depth[id]-1)+detail)
+	}
+	return plan
+}
+
+func (c *instrumentedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e := c.begin(ctx, query, args)
+	result, err := c.SQLiteConn.ExecContext(ctx, query, args)
+	e.end(err)
+	return result, err
+}
+
+func (c *instrumentedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	e := c.begin(ctx, query, args)
+	rows, err := c.SQLiteConn.QueryContext(ctx, query, args)
+	return e.rows(rows, err)
+}
+
+func (c *instrumentedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	stmt, err := c.SQLiteConn.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	sqliteStmt, ok := stmt.(*sqlite3.SQLiteStmt)
+	if !ok {
+		return stmt, nil
+	}
+	return &instrumentedStmt{SQLiteStmt: sqliteStmt, conn: c, query: query}, nil
+}
+
+// instrumentedStmt wraps a prepared statement so that each execution is
+// traced and timed.
+type instrumentedStmt struct {
+	*sqlite3.SQLiteStmt
+	conn  *instrumentedConn
+	query string
+}
+
+func (s *instrumentedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	e := s.conn.begin(ctx, s.query, args)
+	result, err := s.SQLiteStmt.ExecContext(ctx, args)
+	e.end(err)
+	return result, err
+}
+
+func (s *instrumentedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	e := s.conn.begin(ctx, s.query, args)
+	rows, err := s.SQLiteStmt.QueryContext(ctx, args)
+	return e.rows(rows, err)
+}
+
+// rows ends the execution when the rows are closed, so that it covers
+// stepping through the results and not only preparing the statement.
+func (e *execution) rows(rows driver.Rows, err error) (driver.Rows, error) {
+	if err != nil {
+		e.end(err)
+		return rows, err
+	}
+	sqliteRows, ok := rows.(*sqlite3.SQLiteRows)
+	if !ok {
+		e.end(nil)
+		return rows, nil
+	}
+	return &instrumentedRows{SQLiteRows: sqliteRows, execution: e}, nil
+}
+
+type instrumentedRows struct {
+	*sqlite3.SQLiteRows
+	execution *execution
+}
+
+func (r *instrumentedRows) Close() error {
+	err := r.SQLiteRows.Close()
+	r.execution.end(nil)
+	return err
+}
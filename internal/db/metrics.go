@@ -0,0 +1,99 @@
+package db
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"weak"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/prakhar-5447/GoDB/internal/auth"
+	"github.com/prakhar-5447/GoDB/internal/metrics"
+)
+
+var transactions = metrics.Default.NewCounterVec("godb_transactions_total",
+	"SQLite transactions on user databases, including implicit ones, by outcome.", "outcome")
+
+// sqliteDriver opens user database connections. They report committed and
+// rolled back transactions.
+var sqliteDriver = &sqlite3.SQLiteDriver{
+	ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+		conn.RegisterCommitHook(func() int {
+			transactions.Inc("commit")
+			return 0
+		})
+		conn.RegisterRollbackHook(func() { transactions.Inc("rollback") })
+		return nil
+	},
+}
+
+// handles tracks the user database handles the server has opened. Weak
+// pointers let closed handles be collected without an explicit unregister.
+var handles struct {
+	sync.Mutex
+	list []weak.Pointer[sql.DB]
+}
+
+func trackHandle(database *sql.DB) {
+	handles.Lock()
+	defer handles.Unlock()
+	handles.list = append(handles.list, weak.Make(database))
+	// Drop collected handles once the list has doubled since the last sweep.
+	if n := len(handles.list); n >= 64 && n&(n-1) == 0 {
+		live := handles.list[:0]
+		for _, h := range handles.list {
+			if h.Value() != nil {
+				live = append(live, h)
+			}
+		}
+		handles.list = live
+	}
+}
+
+var _ = metrics.Default.NewGaugeFunc("godb_open_database_handles",
+	"User database handles with at least one open SQLite connection.", nil,
+	func(emit func(float64, ...string)) {
+		handles.Lock()
+		defer handles.Unlock()
+		open := 0
+		for _, h := range handles.list {
+			// Closed handles report no open connections.
+			if database := h.Value(); database != nil && database.Stats().OpenConnections > 0 {
+				open++
+			}
+		}
+		emit(float64(open))
+	})
+
+var _ = metrics.Default.NewGaugeFunc("godb_database_size_bytes",
+	"Size of each user database on disk, including its write-ahead log.", []string{"user", "database"},
+	func(emit func(float64, ...string)) {
+		users, err := os.ReadDir(DBDir)
+		if err != nil {
+			return
+		}
+		for _, user := range users {
+			if !user.IsDir() || auth.ValidateUsername(user.Name()) != nil {
+				continue
+			}
+			files, err := os.ReadDir(filepath.Join(DBDir, user.Name()))
+			if err != nil {
+				continue
+			}
+			for _, file := range files {
+				name, ok := strings.CutSuffix(file.Name(), ".db")
+				if !ok || file.IsDir() {
+					continue
+				}
+				var size int64
+				for _, suffix := range []string{".db", ".db-wal"} {
+					if info, err := os.Stat(filepath.Join(DBDir, user.Name(), name+suffix)); err == nil {
+						size += info.Size()
+					}
+				}
+				emit(float64(size), user.Name(), name)
+			}
+		}
+	})
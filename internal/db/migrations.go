@@ -1,25 +1,278 @@
 package db
 
 import (
+	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"fmt"
 	"log"
+	"strings"
 )
 
-// RunMigrations ensures all required tables exist
+// Migration scopes recorded in schema_migrations. Internal migrations are
+// owned by the server; client migrations are submitted via ApplyMigrations.
+const (
+	ScopeInternal = "internal"
+	ScopeClient   = "client"
+)
+
+// Migration is a single versioned schema change.
+type Migration struct {
+	Version int64
+	Name    string
+	// Script is executed when Up is nil.
+	Script string
+	// Up applies the migration inside the given transaction.
+	Up func(tx *sql.Tx) error
+}
+
+// internalMigrations is the ordered registry of server-owned schema changes
+// applied to every user database. Append new entries; never reorder or edit
+// ones that have shipped.
+var internalMigrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_indexes_table",
+		Script: `CREATE TABLE IF NOT EXISTS indexes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			table_name TEXT NOT NULL,
+			index_name TEXT NOT NULL UNIQUE,
+			columns TEXT NOT NULL
+		)`,
+	},
+	{
+		// Databases created by the first RunMigrations carry a user_id
+		// column that AddIndex never fills in, so every insert failed.
+		Version: 2,
+		Name:    "drop_indexes_user_id",
+		Up:      dropIndexesUserID,
+	},
+}
+
+// MigrationResult describes what happened to one migration.
+type MigrationResult struct {
+	Version int64
+	Name    string
+	Applied bool
+}
+
+// RunMigrations ensures all internal migrations have been applied.
 func RunMigrations(db *sql.DB) error {
-	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS indexes (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		user_id TEXT NOT NULL,
-		table_name TEXT NOT NULL,
-		index_name TEXT NOT NULL UNIQUE,
-		columns TEXT NOT NULL,
-		UNIQUE(user_id, index_name)
-	)`)
+	results, err := applyMigrations(db, nil, ScopeInternal, internalMigrations)
 	if err != nil {
 		log.Println("❌ Failed to run migrations:", err)
 		return err
 	}
 
-	log.Println("✅ Migrations applied successfully!")
+	for _, r := range results {
+		if r.Applied {
+			log.Printf("✅ Applied migration %d (%s)", r.Version, r.Name)
+		}
+	}
+	return nil
+}
+
+// ApplyClientMigrations applies client-submitted migrations in order. Each
+// migration is applied at most once; resubmitting an applied version with a
+// different script is an error. Scripts run under the same authorizer as
+// ExecuteSQL, so they cannot attach other databases, write files outside the
+// data directory or touch the server's bookkeeping tables.
+func ApplyClientMigrations(ctx context.Context, db *sql.DB, migrations []Migration) ([]MigrationResult, error) {
+	var last int64
+	for _, m := range migrations {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migration %q: version must be positive", m.Name)
+		}
+		if m.Version <= last {
+			return nil, fmt.Errorf("migration %q: versions must be strictly increasing", m.Name)
+		}
+		if strings.TrimSpace(m.Name) == "" {
+			return nil, fmt.Errorf("migration %d: name is required", m.Version)
+		}
+		if strings.TrimSpace(m.Script) == "" {
+			return nil, fmt.Errorf("migration %d (%s): script is empty", m.Version, m.Name)
+		}
+		last = m.Version
+	}
+	conn, err := RestrictedConn(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return applyMigrations(db, conn, ScopeClient, migrations)
+}
+
+// CurrentVersion returns the highest applied migration version for a scope.
+func CurrentVersion(db *sql.DB, scope string) (int64, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return 0, err
+	}
+	var version sql.NullInt64
+	err := db.QueryRow("SELECT MAX(version) FROM schema_migrations WHERE scope = ?", scope).Scan(&version)
+	if err != nil {
+		return 0, err
+	}
+	return version.Int64, nil
+}
+
+func ensureMigrationsTable(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		scope TEXT NOT NULL,
+		version INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (scope, version)
+	)`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
 	return nil
 }
+
+// applyMigrations applies the migrations of a scope that are not applied yet.
+// When restricted is set, scripts run on it, under its authorizer.
+func applyMigrations(db *sql.DB, restricted *RestrictedConnection, scope string, migrations []Migration) ([]MigrationResult, error) {
+	if err := ensureMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]string{}
+	rows, err := db.Query("SELECT version, checksum FROM schema_migrations WHERE scope = ?", scope)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var version int64
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		applied[version] = checksum
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var results []MigrationResult
+	for _, m := range migrations {
+		sum := migrationChecksum(m)
+		if existing, ok := applied[m.Version]; ok {
+			if existing != sum {
+				return results, fmt.Errorf("migration %d (%s) was already applied with a different script", m.Version, m.Name)
+			}
+			results = append(results, MigrationResult{Version: m.Version, Name: m.Name})
+			continue
+		}
+
+		if err := applyMigration(db, restricted, scope, m, sum); err != nil {
+			return results, fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+		}
+		results = append(results, MigrationResult{Version: m.Version, Name: m.Name, Applied: true})
+	}
+	return results, nil
+}
+
+func applyMigration(db *sql.DB, restricted *RestrictedConnection, scope string, m Migration, checksum string) error {
+	var tx *sql.Tx
+	var err error
+	if restricted != nil {
+		tx, err = restricted.BeginTx(context.Background(), nil)
+	} else {
+		tx, err = db.Begin()
+	}
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	switch {
+	case m.Up != nil:
+		err = m.Up(tx)
+	case restricted != nil:
+		err = restricted.Script(func() error {
+			_, err := tx.Exec(m.Script)
+			return err
+		})
+	default:
+		_, err = tx.Exec(m.Script)
+	}
+	if err != nil {
+		return err
+	}
+
+	record := func() error {
+		_, err := tx.Exec("INSERT INTO schema_migrations (scope, version, name, checksum) VALUES (?, ?, ?, ?)",
+			scope, m.Version, m.Name, checksum)
+		return err
+	}
+	if restricted != nil {
+		// The bookkeeping row is the server's own write, which the
+		// authorizer would refuse.
+		err = restricted.unrestricted(record)
+	} else {
+		err = record()
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// migrationChecksum fingerprints a migration so edits to applied scripts are
+// detected. Go-coded migrations are identified by name only.
+func migrationChecksum(m Migration) string {
+	sum := sha256.Sum256([]byte(m.Name + "\x00" + m.Script))
+	return hex.EncodeToString(sum[:])
+}
+
+// dropIndexesUserID rebuilds a legacy indexes table without its user_id column.
+func dropIndexesUserID(tx *sql.Tx) error {
+	hasUserID, err := tableHasColumn(tx, "indexes", "user_id")
+	if err != nil || !hasUserID {
+		return err
+	}
+
+	statements := []string{
+		`CREATE TABLE indexes_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			table_name TEXT NOT NULL,
+			index_name TEXT NOT NULL UNIQUE,
+			columns TEXT NOT NULL
+		)`,
+		`INSERT INTO indexes_new (id, table_name, index_name, columns)
+			SELECT id, table_name, index_name, columns FROM indexes`,
+		`DROP TABLE indexes`,
+		`ALTER TABLE indexes_new RENAME TO indexes`,
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func tableHasColumn(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			return false, err
+		}
+		if strings.EqualFold(name, column) {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
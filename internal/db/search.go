@@ -0,0 +1,141 @@
+package db
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// IndexOriginSearch marks full-text search indexes in index listings.
+const IndexOriginSearch = "fts"
+
+// searchShadowSuffixes are the tables FTS5 creates alongside a virtual table.
+var searchShadowSuffixes = []string{"_data", "_idx", "_docsize", "_config", "_content"}
+
+// SearchIndexInfo describes an FTS5 virtual table indexing columns of a
+// regular table, as created by CreateSearchIndex.
+type SearchIndexInfo struct {
+	Name      string
+	Table     string
+	Columns   []string
+	Tokenizer string
+	SQL       string
+}
+
+// SearchTriggerNames returns the names of the triggers that keep a search
+// index in sync with its table.
+func SearchTriggerNames(index string) []string {
+	return []string{index + "_ai", index + "_ad", index + "_au"}
+}
+
+// SearchIndexes lists the FTS5 search indexes over table, or over every
+// table when table is empty. Only external-content FTS5 tables are
+// considered; they are the ones CreateSearchIndex builds.
+func SearchIndexes(db Execer, table string) ([]SearchIndexInfo, error) {
+	rows, err := db.Query("SELECT name, sql FROM sqlite_master WHERE type = 'table' AND sql LIKE 'CREATE VIRTUAL TABLE%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []SearchIndexInfo
+	for rows.Next() {
+		var name string
+		var createSQL sql.NullString
+		if err := rows.Scan(&name, &createSQL); err != nil {
+			return nil, err
+		}
+		info, ok := parseSearchIndex(name, createSQL.String)
+		if !ok || (table != "" && !strings.EqualFold(info.Table, table)) {
+			continue
+		}
+		indexes = append(indexes, info)
+	}
+	return indexes, rows.Err()
+}
+
+// SearchIndex looks up a search index by name.
+func SearchIndex(db Execer, name string) (SearchIndexInfo, bool, error) {
+	var createSQL sql.NullString
+	err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&createSQL)
+	if err == sql.ErrNoRows {
+		return SearchIndexInfo{}, false, nil
+	}
+	if err != nil {
+		return SearchIndexInfo{}, false, err
+	}
+	info, ok := parseSearchIndex(name, createSQL.String)
+	return info, ok, nil
+}
+
+// parseSearchIndex reads a CREATE VIRTUAL TABLE ... USING fts5(...) statement.
+func parseSearchIndex(name, stmt string) (SearchIndexInfo, bool) {
+	upper := strings.ToUpper(stmt)
+	using := strings.Index(upper, " USING FTS5")
+	if using < 0 {
+		return SearchIndexInfo{}, false
+	}
+	info := SearchIndexInfo{Name: name, SQL: stmt}
+	terms, _ := splitIndexSQL(stmt[using:])
+	for _, term := range terms {
+		key, value, isOption := strings.Cut(term, "=")
+		if !isOption {
+			info.Columns = append(info.Columns, unquote(strings.Fields(term)[0]))
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "content":
+			info.Table = unquote(strings.TrimSpace(value))
+		case "tokenize":
+			info.Tokenizer = unquote(strings.TrimSpace(value))
+		}
+	}
+	if info.Table == "" {
+		return SearchIndexInfo{}, false
+	}
+	return info, true
+}
+
+// IsSearchShadowTable reports whether table is one of the tables FTS5
+// maintains for a search index. Writing to them corrupts the index.
+func IsSearchShadowTable(db Execer, table string) (bool, error) {
+	lower := strings.ToLower(table)
+	for _, suffix := range searchShadowSuffixes {
+		base, ok := strings.CutSuffix(lower, suffix)
+		if !ok {
+			continue
+		}
+		var count int
+		err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ? COLLATE NOCASE AND sql LIKE 'CREATE VIRTUAL TABLE%'", base).Scan(&count)
+		if err != nil || count > 0 {
+			return count > 0, err
+		}
+	}
+	return false, nil
+}
+
+// isSearchShadowTable reports whether name is an FTS5 shadow table of one of
+// the given virtual tables.
+func isSearchShadowTable(name string, virtual map[string]bool) bool {
+	lower := strings.ToLower(name)
+	for _, suffix := range searchShadowSuffixes {
+		if base, ok := strings.CutSuffix(lower, suffix); ok && virtual[base] {
+			return true
+		}
+	}
+	return false
+}
+
+// unquote strips SQL quotes from an identifier or string literal.
+func unquote(s string) string {
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"':
+			return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+		case s[0] == '\'' && s[len(s)-1] == '\'':
+			return strings.ReplaceAll(s[1:len(s)-1], `''`, `'`)
+		case s[0] == '`' && s[len(s)-1] == '`', s[0] == '[' && s[len(s)-1] == ']':
+			return s[1 : len(s)-1]
+		}
+	}
+	return s
+}
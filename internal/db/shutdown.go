@@ -0,0 +1,27 @@
+package db
+
+import "errors"
+
+// Shutdown is called once RPCs have drained. It closes the user database
+// handles the server opened. database/sql closes idle connections at once
+// and those still held by an RPC that outlived the drain deadline when the
+// RPC releases them, so no connection is used by two goroutines. Closing a
+// connection rolls back a transaction left open on it, and closing the last
+// connection to a database checkpoints its write-ahead log into the main
+// file.
+func Shutdown() error {
+	handles.Lock()
+	list := handles.list
+	handles.list = nil
+	handles.Unlock()
+
+	var errs []error
+	for _, h := range list {
+		if database := h.Value(); database != nil {
+			if err := database.Close(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,111 @@
+// Package health serves the standard grpc.health.v1 service. The server and
+// each of its services are reported as SERVING only while every dependency
+// probe passes, so load balancers stop routing to an instance that has lost
+// its auth database or data directory.
+package health
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Interval is the time between probe rounds.
+var Interval = 10 * time.Second
+
+// ProbeTimeout bounds a single probe.
+var ProbeTimeout = 2 * time.Second
+
+// Probe checks one dependency of the server.
+type Probe struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Checker runs probes and publishes their outcome through the health service.
+type Checker struct {
+	server   *grpchealth.Server
+	services []string
+	probes   []Probe
+
+	mu      sync.Mutex
+	failure string // the last failure logged; empty while serving
+}
+
+// Register adds the health service to s. The overall status ("") and the
+// status of every service already registered on s follow the probes; they
+// are NOT_SERVING until the first round has passed.
+func Register(s *grpc.Server, probes ...Probe) *Checker {
+	c := &Checker{server: grpchealth.NewServer(), services: []string{""}, probes: probes, failure: "not probed yet"}
+	for name := range s.GetServiceInfo() {
+		c.services = append(c.services, name)
+	}
+	c.publish(healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(s, c.server)
+	return c
+}
+
+// Start probes immediately and then every Interval until ctx is done.
+func (c *Checker) Start(ctx context.Context) {
+	c.probe(ctx)
+	go func() {
+		ticker := time.NewTicker(Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.probe(ctx)
+			}
+		}
+	}()
+}
+
+// Shutdown reports every service as NOT_SERVING for good, so that clients
+// drain away while the server stops.
+func (c *Checker) Shutdown() {
+	c.server.Shutdown()
+}
+
+// probe runs every probe and publishes the combined status, logging changes.
+func (c *Checker) probe(ctx context.Context) {
+	var failures []string
+	for _, p := range c.probes {
+		probeCtx, cancel := context.WithTimeout(ctx, ProbeTimeout)
+		if err := p.Check(probeCtx); err != nil {
+			failures = append(failures, p.Name+": "+err.Error())
+		}
+		cancel()
+	}
+	failure := strings.Join(failures, "; ")
+
+	c.mu.Lock()
+	changed := failure != c.failure
+	c.failure = failure
+	c.mu.Unlock()
+
+	if failure != "" {
+		c.publish(healthpb.HealthCheckResponse_NOT_SERVING)
+		if changed {
+			log.Printf("Health: not serving: %s", failure)
+		}
+		return
+	}
+	c.publish(healthpb.HealthCheckResponse_SERVING)
+	if changed {
+		log.Println("Health: serving")
+	}
+}
+
+func (c *Checker) publish(status healthpb.HealthCheckResponse_ServingStatus) {
+	for _, name := range c.services {
+		c.server.SetServingStatus(name, status)
+	}
+}
@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	rpcRequests = Default.NewCounterVec("godb_rpc_requests_total",
+		"RPCs handled, by method and gRPC status code.", "method", "code")
+	rpcDuration = Default.NewHistogramVec("godb_rpc_duration_seconds",
+		"RPC latency in seconds, by method.", DefaultBuckets, "method")
+
+	// AuthFailures counts rejected credentials.
+	AuthFailures = Default.NewCounterVec("godb_auth_failures_total",
+		"Connection attempts rejected because of invalid credentials.")
+)
+
+// UnaryServerInterceptor counts every unary RPC and records its latency.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	method := path.Base(info.FullMethod)
+	rpcRequests.Inc(method, status.Code(err).String())
+	rpcDuration.Observe(time.Since(start).Seconds(), method)
+	return resp, err
+}
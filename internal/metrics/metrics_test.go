@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := &Registry{}
+
+	requests := r.NewCounterVec("test_requests_total", "Requests handled.", "method", "code")
+	requests.Inc("Query", "OK")
+	requests.Inc("Query", "OK")
+	requests.Add(0.5, "Delete", `bad "quote"`)
+	r.NewCounterVec("test_failures_total", "Failures.\nSecond line.")
+
+	duration := r.NewHistogramVec("test_duration_seconds", "Request duration.", []float64{0.1, 1}, "method")
+	duration.Observe(0.05, "Query")
+	duration.Observe(0.1, "Query")
+	duration.Observe(0.5, "Query")
+	duration.Observe(3, "Query")
+
+	r.NewGaugeFunc("test_size_bytes", "Database size.", []string{"user", "database"}, func(emit func(float64, ...string)) {
+		emit(2048, "bob", "shop")
+		emit(1024, "alice", "notes")
+	})
+
+	var out strings.Builder
+	r.WriteText(&out)
+
+	want := `# HELP test_requests_total Requests handled.
+# TYPE test_requests_total counter
+test_requests_total{method="Delete",code="bad \"quote\""} 0.5
+test_requests_total{method="Query",code="OK"} 2
+# HELP test_failures_total Failures.\nSecond line.
+# TYPE test_failures_total counter
+test_failures_total 0
+# HELP test_duration_seconds Request duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{method="Query",le="0.1"} 2
+test_duration_seconds_bucket{method="Query",le="1"} 3
+test_duration_seconds_bucket{method="Query",le="+Inf"} 4
+test_duration_seconds_sum{method="Query"} 3.65
+test_duration_seconds_count{method="Query"} 4
+# HELP test_size_bytes Database size.
+# TYPE test_size_bytes gauge
+test_size_bytes{user="alice",database="notes"} 1024
+test_size_bytes{user="bob",database="shop"} 2048
+`
+	if got := out.String(); got != want {
+		t.Errorf("WriteText output mismatch\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
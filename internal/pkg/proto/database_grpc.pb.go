@@ -31,6 +31,26 @@ const (
 	DatabaseService_AddIndex_FullMethodName              = "/proto.DatabaseService/AddIndex"
 	DatabaseService_DeleteIndex_FullMethodName           = "/proto.DatabaseService/DeleteIndex"
 	DatabaseService_ListIndexes_FullMethodName           = "/proto.DatabaseService/ListIndexes"
+	DatabaseService_ApplyMigrations_FullMethodName       = "/proto.DatabaseService/ApplyMigrations"
+	DatabaseService_RepairIndexMetadata_FullMethodName   = "/proto.DatabaseService/RepairIndexMetadata"
+	DatabaseService_ExplainQuery_FullMethodName          = "/proto.DatabaseService/ExplainQuery"
+	DatabaseService_GetIndexUsage_FullMethodName         = "/proto.DatabaseService/GetIndexUsage"
+	DatabaseService_RecommendIndexes_FullMethodName      = "/proto.DatabaseService/RecommendIndexes"
+	DatabaseService_SetIndexAdvisorPolicy_FullMethodName = "/proto.DatabaseService/SetIndexAdvisorPolicy"
+	DatabaseService_SetUserRole_FullMethodName           = "/proto.DatabaseService/SetUserRole"
+	DatabaseService_AggregateQuery_FullMethodName        = "/proto.DatabaseService/AggregateQuery"
+	DatabaseService_JoinQuery_FullMethodName             = "/proto.DatabaseService/JoinQuery"
+	DatabaseService_CountRecords_FullMethodName          = "/proto.DatabaseService/CountRecords"
+	DatabaseService_Exists_FullMethodName                = "/proto.DatabaseService/Exists"
+	DatabaseService_ExecuteSQL_FullMethodName            = "/proto.DatabaseService/ExecuteSQL"
+	DatabaseService_PrepareStatement_FullMethodName      = "/proto.DatabaseService/PrepareStatement"
+	DatabaseService_ExecutePrepared_FullMethodName       = "/proto.DatabaseService/ExecutePrepared"
+	DatabaseService_ClosePrepared_FullMethodName         = "/proto.DatabaseService/ClosePrepared"
+	DatabaseService_CreateSearchIndex_FullMethodName     = "/proto.DatabaseService/CreateSearchIndex"
+	DatabaseService_Search_FullMethodName                = "/proto.DatabaseService/Search"
+	DatabaseService_VerifyAuditLog_FullMethodName        = "/proto.DatabaseService/VerifyAuditLog"
+	DatabaseService_QueryAuditLog_FullMethodName         = "/proto.DatabaseService/QueryAuditLog"
+	DatabaseService_TopSlowQueries_FullMethodName        = "/proto.DatabaseService/TopSlowQueries"
 )
 
 // DatabaseServiceClient is the client API for DatabaseService service.
@@ -49,6 +69,26 @@ type DatabaseServiceClient interface {
 	AddIndex(ctx context.Context, in *AddIndexRequest, opts ...grpc.CallOption) (*AddIndexResponse, error)
 	DeleteIndex(ctx context.Context, in *DeleteIndexRequest, opts ...grpc.CallOption) (*DeleteIndexResponse, error)
 	ListIndexes(ctx context.Context, in *ListIndexesRequest, opts ...grpc.CallOption) (*ListIndexesResponse, error)
+	ApplyMigrations(ctx context.Context, in *ApplyMigrationsRequest, opts ...grpc.CallOption) (*ApplyMigrationsResponse, error)
+	RepairIndexMetadata(ctx context.Context, in *RepairIndexMetadataRequest, opts ...grpc.CallOption) (*RepairIndexMetadataResponse, error)
+	ExplainQuery(ctx context.Context, in *QueryDataRequest, opts ...grpc.CallOption) (*ExplainQueryResponse, error)
+	GetIndexUsage(ctx context.Context, in *IndexUsageRequest, opts ...grpc.CallOption) (*IndexUsageResponse, error)
+	RecommendIndexes(ctx context.Context, in *RecommendIndexesRequest, opts ...grpc.CallOption) (*RecommendIndexesResponse, error)
+	SetIndexAdvisorPolicy(ctx context.Context, in *SetIndexAdvisorPolicyRequest, opts ...grpc.CallOption) (*SetIndexAdvisorPolicyResponse, error)
+	SetUserRole(ctx context.Context, in *SetUserRoleRequest, opts ...grpc.CallOption) (*SetUserRoleResponse, error)
+	AggregateQuery(ctx context.Context, in *AggregateQueryRequest, opts ...grpc.CallOption) (*AggregateQueryResponse, error)
+	JoinQuery(ctx context.Context, in *JoinQueryRequest, opts ...grpc.CallOption) (*JoinQueryResponse, error)
+	CountRecords(ctx context.Context, in *CountRecordsRequest, opts ...grpc.CallOption) (*CountRecordsResponse, error)
+	Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error)
+	ExecuteSQL(ctx context.Context, in *ExecuteSQLRequest, opts ...grpc.CallOption) (*ExecuteSQLResponse, error)
+	PrepareStatement(ctx context.Context, in *PrepareStatementRequest, opts ...grpc.CallOption) (*PrepareStatementResponse, error)
+	ExecutePrepared(ctx context.Context, in *ExecutePreparedRequest, opts ...grpc.CallOption) (*ExecutePreparedResponse, error)
+	ClosePrepared(ctx context.Context, in *ClosePreparedRequest, opts ...grpc.CallOption) (*ClosePreparedResponse, error)
+	CreateSearchIndex(ctx context.Context, in *CreateSearchIndexRequest, opts ...grpc.CallOption) (*CreateSearchIndexResponse, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	VerifyAuditLog(ctx context.Context, in *VerifyAuditLogRequest, opts ...grpc.CallOption) (*VerifyAuditLogResponse, error)
+	QueryAuditLog(ctx context.Context, in *QueryAuditLogRequest, opts ...grpc.CallOption) (*QueryAuditLogResponse, error)
+	TopSlowQueries(ctx context.Context, in *TopSlowQueriesRequest, opts ...grpc.CallOption) (*TopSlowQueriesResponse, error)
 }
 
 type databaseServiceClient struct {
@@ -179,6 +219,206 @@ func (c *databaseServiceClient) ListIndexes(ctx context.Context, in *ListIndexes
 	return out, nil
 }
 
+func (c *databaseServiceClient) ApplyMigrations(ctx context.Context, in *ApplyMigrationsRequest, opts ...grpc.CallOption) (*ApplyMigrationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyMigrationsResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_ApplyMigrations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) RepairIndexMetadata(ctx context.Context, in *RepairIndexMetadataRequest, opts ...grpc.CallOption) (*RepairIndexMetadataResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RepairIndexMetadataResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_RepairIndexMetadata_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) ExplainQuery(ctx context.Context, in *QueryDataRequest, opts ...grpc.CallOption) (*ExplainQueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExplainQueryResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_ExplainQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) GetIndexUsage(ctx context.Context, in *IndexUsageRequest, opts ...grpc.CallOption) (*IndexUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IndexUsageResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_GetIndexUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) RecommendIndexes(ctx context.Context, in *RecommendIndexesRequest, opts ...grpc.CallOption) (*RecommendIndexesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecommendIndexesResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_RecommendIndexes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) SetIndexAdvisorPolicy(ctx context.Context, in *SetIndexAdvisorPolicyRequest, opts ...grpc.CallOption) (*SetIndexAdvisorPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetIndexAdvisorPolicyResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_SetIndexAdvisorPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) SetUserRole(ctx context.Context, in *SetUserRoleRequest, opts ...grpc.CallOption) (*SetUserRoleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetUserRoleResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_SetUserRole_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) AggregateQuery(ctx context.Context, in *AggregateQueryRequest, opts ...grpc.CallOption) (*AggregateQueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AggregateQueryResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_AggregateQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) JoinQuery(ctx context.Context, in *JoinQueryRequest, opts ...grpc.CallOption) (*JoinQueryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(JoinQueryResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_JoinQuery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) CountRecords(ctx context.Context, in *CountRecordsRequest, opts ...grpc.CallOption) (*CountRecordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CountRecordsResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_CountRecords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) Exists(ctx context.Context, in *ExistsRequest, opts ...grpc.CallOption) (*ExistsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExistsResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_Exists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) ExecuteSQL(ctx context.Context, in *ExecuteSQLRequest, opts ...grpc.CallOption) (*ExecuteSQLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecuteSQLResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_ExecuteSQL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) PrepareStatement(ctx context.Context, in *PrepareStatementRequest, opts ...grpc.CallOption) (*PrepareStatementResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PrepareStatementResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_PrepareStatement_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) ExecutePrepared(ctx context.Context, in *ExecutePreparedRequest, opts ...grpc.CallOption) (*ExecutePreparedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecutePreparedResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_ExecutePrepared_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) ClosePrepared(ctx context.Context, in *ClosePreparedRequest, opts ...grpc.CallOption) (*ClosePreparedResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClosePreparedResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_ClosePrepared_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) CreateSearchIndex(ctx context.Context, in *CreateSearchIndexRequest, opts ...grpc.CallOption) (*CreateSearchIndexResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateSearchIndexResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_CreateSearchIndex_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) VerifyAuditLog(ctx context.Context, in *VerifyAuditLogRequest, opts ...grpc.CallOption) (*VerifyAuditLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyAuditLogResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_VerifyAuditLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) QueryAuditLog(ctx context.Context, in *QueryAuditLogRequest, opts ...grpc.CallOption) (*QueryAuditLogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueryAuditLogResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_QueryAuditLog_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *databaseServiceClient) TopSlowQueries(ctx context.Context, in *TopSlowQueriesRequest, opts ...grpc.CallOption) (*TopSlowQueriesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TopSlowQueriesResponse)
+	err := c.cc.Invoke(ctx, DatabaseService_TopSlowQueries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // DatabaseServiceServer is the server API for DatabaseService service.
 // All implementations must embed UnimplementedDatabaseServiceServer
 // for forward compatibility.
@@ -195,6 +435,26 @@ type DatabaseServiceServer interface {
 	AddIndex(context.Context, *AddIndexRequest) (*AddIndexResponse, error)
 	DeleteIndex(context.Context, *DeleteIndexRequest) (*DeleteIndexResponse, error)
 	ListIndexes(context.Context, *ListIndexesRequest) (*ListIndexesResponse, error)
+	ApplyMigrations(context.Context, *ApplyMigrationsRequest) (*ApplyMigrationsResponse, error)
+	RepairIndexMetadata(context.Context, *RepairIndexMetadataRequest) (*RepairIndexMetadataResponse, error)
+	ExplainQuery(context.Context, *QueryDataRequest) (*ExplainQueryResponse, error)
+	GetIndexUsage(context.Context, *IndexUsageRequest) (*IndexUsageResponse, error)
+	RecommendIndexes(context.Context, *RecommendIndexesRequest) (*RecommendIndexesResponse, error)
+	SetIndexAdvisorPolicy(context.Context, *SetIndexAdvisorPolicyRequest) (*SetIndexAdvisorPolicyResponse, error)
+	SetUserRole(context.Context, *SetUserRoleRequest) (*SetUserRoleResponse, error)
+	AggregateQuery(context.Context, *AggregateQueryRequest) (*AggregateQueryResponse, error)
+	JoinQuery(context.Context, *JoinQueryRequest) (*JoinQueryResponse, error)
+	CountRecords(context.Context, *CountRecordsRequest) (*CountRecordsResponse, error)
+	Exists(context.Context, *ExistsRequest) (*ExistsResponse, error)
+	ExecuteSQL(context.Context, *ExecuteSQLRequest) (*ExecuteSQLResponse, error)
+	PrepareStatement(context.Context, *PrepareStatementRequest) (*PrepareStatementResponse, error)
+	ExecutePrepared(context.Context, *ExecutePreparedRequest) (*ExecutePreparedResponse, error)
+	ClosePrepared(context.Context, *ClosePreparedRequest) (*ClosePreparedResponse, error)
+	CreateSearchIndex(context.Context, *CreateSearchIndexRequest) (*CreateSearchIndexResponse, error)
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	VerifyAuditLog(context.Context, *VerifyAuditLogRequest) (*VerifyAuditLogResponse, error)
+	QueryAuditLog(context.Context, *QueryAuditLogRequest) (*QueryAuditLogResponse, error)
+	TopSlowQueries(context.Context, *TopSlowQueriesRequest) (*TopSlowQueriesResponse, error)
 	mustEmbedUnimplementedDatabaseServiceServer()
 }
 
@@ -241,6 +501,66 @@ func (UnimplementedDatabaseServiceServer) DeleteIndex(context.Context, *DeleteIn
 func (UnimplementedDatabaseServiceServer) ListIndexes(context.Context, *ListIndexesRequest) (*ListIndexesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListIndexes not implemented")
 }
+func (UnimplementedDatabaseServiceServer) ApplyMigrations(context.Context, *ApplyMigrationsRequest) (*ApplyMigrationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApplyMigrations not implemented")
+}
+func (UnimplementedDatabaseServiceServer) RepairIndexMetadata(context.Context, *RepairIndexMetadataRequest) (*RepairIndexMetadataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RepairIndexMetadata not implemented")
+}
+func (UnimplementedDatabaseServiceServer) ExplainQuery(context.Context, *QueryDataRequest) (*ExplainQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExplainQuery not implemented")
+}
+func (UnimplementedDatabaseServiceServer) GetIndexUsage(context.Context, *IndexUsageRequest) (*IndexUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIndexUsage not implemented")
+}
+func (UnimplementedDatabaseServiceServer) RecommendIndexes(context.Context, *RecommendIndexesRequest) (*RecommendIndexesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RecommendIndexes not implemented")
+}
+func (UnimplementedDatabaseServiceServer) SetIndexAdvisorPolicy(context.Context, *SetIndexAdvisorPolicyRequest) (*SetIndexAdvisorPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetIndexAdvisorPolicy not implemented")
+}
+func (UnimplementedDatabaseServiceServer) SetUserRole(context.Context, *SetUserRoleRequest) (*SetUserRoleResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUserRole not implemented")
+}
+func (UnimplementedDatabaseServiceServer) AggregateQuery(context.Context, *AggregateQueryRequest) (*AggregateQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AggregateQuery not implemented")
+}
+func (UnimplementedDatabaseServiceServer) JoinQuery(context.Context, *JoinQueryRequest) (*JoinQueryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method JoinQuery not implemented")
+}
+func (UnimplementedDatabaseServiceServer) CountRecords(context.Context, *CountRecordsRequest) (*CountRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CountRecords not implemented")
+}
+func (UnimplementedDatabaseServiceServer) Exists(context.Context, *ExistsRequest) (*ExistsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Exists not implemented")
+}
+func (UnimplementedDatabaseServiceServer) ExecuteSQL(context.Context, *ExecuteSQLRequest) (*ExecuteSQLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteSQL not implemented")
+}
+func (UnimplementedDatabaseServiceServer) PrepareStatement(context.Context, *PrepareStatementRequest) (*PrepareStatementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PrepareStatement not implemented")
+}
+func (UnimplementedDatabaseServiceServer) ExecutePrepared(context.Context, *ExecutePreparedRequest) (*ExecutePreparedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecutePrepared not implemented")
+}
+func (UnimplementedDatabaseServiceServer) ClosePrepared(context.Context, *ClosePreparedRequest) (*ClosePreparedResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClosePrepared not implemented")
+}
+func (UnimplementedDatabaseServiceServer) CreateSearchIndex(context.Context, *CreateSearchIndexRequest) (*CreateSearchIndexResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSearchIndex not implemented")
+}
+func (UnimplementedDatabaseServiceServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedDatabaseServiceServer) VerifyAuditLog(context.Context, *VerifyAuditLogRequest) (*VerifyAuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyAuditLog not implemented")
+}
+func (UnimplementedDatabaseServiceServer) QueryAuditLog(context.Context, *QueryAuditLogRequest) (*QueryAuditLogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method QueryAuditLog not implemented")
+}
+func (UnimplementedDatabaseServiceServer) TopSlowQueries(context.Context, *TopSlowQueriesRequest) (*TopSlowQueriesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TopSlowQueries not implemented")
+}
 func (UnimplementedDatabaseServiceServer) mustEmbedUnimplementedDatabaseServiceServer() {}
 func (UnimplementedDatabaseServiceServer) testEmbeddedByValue()                         {}
 
@@ -478,6 +798,366 @@ func _DatabaseService_ListIndexes_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _DatabaseService_ApplyMigrations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyMigrationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).ApplyMigrations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_ApplyMigrations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).ApplyMigrations(ctx, req.(*ApplyMigrationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_RepairIndexMetadata_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RepairIndexMetadataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).RepairIndexMetadata(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_RepairIndexMetadata_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).RepairIndexMetadata(ctx, req.(*RepairIndexMetadataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_ExplainQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).ExplainQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_ExplainQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).ExplainQuery(ctx, req.(*QueryDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_GetIndexUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IndexUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).GetIndexUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_GetIndexUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).GetIndexUsage(ctx, req.(*IndexUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_RecommendIndexes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecommendIndexesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).RecommendIndexes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_RecommendIndexes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).RecommendIndexes(ctx, req.(*RecommendIndexesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_SetIndexAdvisorPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetIndexAdvisorPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).SetIndexAdvisorPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_SetIndexAdvisorPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).SetIndexAdvisorPolicy(ctx, req.(*SetIndexAdvisorPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_SetUserRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).SetUserRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_SetUserRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).SetUserRole(ctx, req.(*SetUserRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_AggregateQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AggregateQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).AggregateQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_AggregateQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).AggregateQuery(ctx, req.(*AggregateQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_JoinQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(JoinQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).JoinQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_JoinQuery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).JoinQuery(ctx, req.(*JoinQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_CountRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).CountRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_CountRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).CountRecords(ctx, req.(*CountRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_Exists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).Exists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_Exists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).Exists(ctx, req.(*ExistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_ExecuteSQL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteSQLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).ExecuteSQL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_ExecuteSQL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).ExecuteSQL(ctx, req.(*ExecuteSQLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_PrepareStatement_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrepareStatementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).PrepareStatement(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_PrepareStatement_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).PrepareStatement(ctx, req.(*PrepareStatementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_ExecutePrepared_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecutePreparedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).ExecutePrepared(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_ExecutePrepared_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).ExecutePrepared(ctx, req.(*ExecutePreparedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_ClosePrepared_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClosePreparedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).ClosePrepared(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_ClosePrepared_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).ClosePrepared(ctx, req.(*ClosePreparedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_CreateSearchIndex_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSearchIndexRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).CreateSearchIndex(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_CreateSearchIndex_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).CreateSearchIndex(ctx, req.(*CreateSearchIndexRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_VerifyAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).VerifyAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_VerifyAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).VerifyAuditLog(ctx, req.(*VerifyAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_QueryAuditLog_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueryAuditLogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).QueryAuditLog(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_QueryAuditLog_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).QueryAuditLog(ctx, req.(*QueryAuditLogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DatabaseService_TopSlowQueries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopSlowQueriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DatabaseServiceServer).TopSlowQueries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DatabaseService_TopSlowQueries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DatabaseServiceServer).TopSlowQueries(ctx, req.(*TopSlowQueriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // DatabaseService_ServiceDesc is the grpc.ServiceDesc for DatabaseService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -533,6 +1213,86 @@ var DatabaseService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListIndexes",
 			Handler:    _DatabaseService_ListIndexes_Handler,
 		},
+		{
+			MethodName: "ApplyMigrations",
+			Handler:    _DatabaseService_ApplyMigrations_Handler,
+		},
+		{
+			MethodName: "RepairIndexMetadata",
+			Handler:    _DatabaseService_RepairIndexMetadata_Handler,
+		},
+		{
+			MethodName: "ExplainQuery",
+			Handler:    _DatabaseService_ExplainQuery_Handler,
+		},
+		{
+			MethodName: "GetIndexUsage",
+			Handler:    _DatabaseService_GetIndexUsage_Handler,
+		},
+		{
+			MethodName: "RecommendIndexes",
+			Handler:    _DatabaseService_RecommendIndexes_Handler,
+		},
+		{
+			MethodName: "SetIndexAdvisorPolicy",
+			Handler:    _DatabaseService_SetIndexAdvisorPolicy_Handler,
+		},
+		{
+			MethodName: "SetUserRole",
+			Handler:    _DatabaseService_SetUserRole_Handler,
+		},
+		{
+			MethodName: "AggregateQuery",
+			Handler:    _DatabaseService_AggregateQuery_Handler,
+		},
+		{
+			MethodName: "JoinQuery",
+			Handler:    _DatabaseService_JoinQuery_Handler,
+		},
+		{
+			MethodName: "CountRecords",
+			Handler:    _DatabaseService_CountRecords_Handler,
+		},
+		{
+			MethodName: "Exists",
+			Handler:    _DatabaseService_Exists_Handler,
+		},
+		{
+			MethodName: "ExecuteSQL",
+			Handler:    _DatabaseService_ExecuteSQL_Handler,
+		},
+		{
+			MethodName: "PrepareStatement",
+			Handler:    _DatabaseService_PrepareStatement_Handler,
+		},
+		{
+			MethodName: "ExecutePrepared",
+			Handler:    _DatabaseService_ExecutePrepared_Handler,
+		},
+		{
+			MethodName: "ClosePrepared",
+			Handler:    _DatabaseService_ClosePrepared_Handler,
+		},
+		{
+			MethodName: "CreateSearchIndex",
+			Handler:    _DatabaseService_CreateSearchIndex_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _DatabaseService_Search_Handler,
+		},
+		{
+			MethodName: "VerifyAuditLog",
+			Handler:    _DatabaseService_VerifyAuditLog_Handler,
+		},
+		{
+			MethodName: "QueryAuditLog",
+			Handler:    _DatabaseService_QueryAuditLog_Handler,
+		},
+		{
+			MethodName: "TopSlowQueries",
+			Handler:    _DatabaseService_TopSlowQueries_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "database.proto",
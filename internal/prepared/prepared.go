@@ -0,0 +1,274 @@
+// Package prepared keeps compiled statements alive between RPCs. Statements
+// are grouped into sessions, one per database handle, and each session holds
+// at most MaxStatementsPerSession statements, evicting the least recently
+// used. A session's database handle is closed with its last statement, when
+// the session has been idle for SessionIdleTimeout, or when its user opens
+// more than MaxSessionsPerUser sessions.
+package prepared
+
+import (
+	"container/list"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prakhar-5447/GoDB/internal/query"
+)
+
+// MaxStatementsPerSession bounds the statements cached for one database handle.
+var MaxStatementsPerSession = 64
+
+// MaxSessionsPerUser bounds the sessions, and so the database handles, one
+// user keeps open. A new session beyond it evicts the user's least recently
+// used one. Zero means no limit.
+var MaxSessionsPerUser = 8
+
+// SessionIdleTimeout closes sessions whose statements have not been prepared
+// or executed for that long. Zero keeps idle sessions open.
+var SessionIdleTimeout = 15 * time.Minute
+
+// ErrNotFound is returned for unknown, closed or evicted statements.
+var ErrNotFound = errors.New("prepared statement not found")
+
+// ErrTooManySessions is returned when a user's sessions are all busy
+// preparing statements and none can be evicted for a new one.
+var ErrTooManySessions = errors.New("too many prepared statement sessions")
+
+// Kind tells how a prepared statement is executed.
+type Kind int
+
+const (
+	// KindQuery statements return rows.
+	KindQuery Kind = iota
+	// KindExec statements return an affected row count.
+	KindExec
+)
+
+// Statement is a compiled statement cached in a session.
+type Statement struct {
+	ID   string
+	Kind Kind
+	*query.Statement
+	// Stmt is the driver-level prepared statement.
+	Stmt *sql.Stmt
+}
+
+type session struct {
+	key      string
+	user     string
+	db       *sql.DB
+	order    *list.List // of *Statement, most recently used first
+	byID     map[string]*list.Element
+	lastUsed time.Time
+	// preparing counts statements being compiled outside the lock. The
+	// session is neither evicted nor closed while any are.
+	preparing int
+	closed    bool
+}
+
+var (
+	mu       sync.Mutex
+	sessions = map[string]*session{}
+)
+
+// Prepare compiles stmt in the session of user identified by key. open is
+// called to obtain the database handle when the session does not exist yet;
+// build compiles the statement against that handle. Neither runs with the
+// package lock held.
+func Prepare(key, user string, kind Kind, open func() (*sql.DB, error), build func(*sql.DB) (*query.Statement, error)) (*Statement, error) {
+	s, err := acquire(key, user, open)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry *Statement
+	stmt, err := build(s.db)
+	if err == nil {
+		var compiled *sql.Stmt
+		if compiled, err = s.db.Prepare(stmt.SQL); err == nil {
+			entry = &Statement{ID: newID(), Kind: kind, Statement: stmt, Stmt: compiled}
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	s.preparing--
+	switch {
+	case entry != nil && s.closed:
+		// CloseAll ran while the statement was compiled.
+		entry.Stmt.Close()
+		return nil, ErrNotFound
+	case entry != nil:
+		s.byID[entry.ID] = s.order.PushFront(entry)
+		for s.order.Len() > MaxStatementsPerSession {
+			s.remove(s.order.Back())
+		}
+		return entry, nil
+	}
+	if s.order.Len() == 0 && s.preparing == 0 {
+		s.close()
+	}
+	return nil, err
+}
+
+// acquire returns the session for key, opening it if needed, with preparing
+// incremented.
+func acquire(key, user string, open func() (*sql.DB, error)) (*session, error) {
+	mu.Lock()
+	now := time.Now()
+	expire(now)
+	if s, ok := sessions[key]; ok {
+		s.preparing++
+		s.lastUsed = now
+		mu.Unlock()
+		return s, nil
+	}
+	mu.Unlock()
+
+	database, err := open()
+	if err != nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if s, ok := sessions[key]; ok {
+		// Another Prepare opened the session meanwhile.
+		database.Close()
+		s.preparing++
+		s.lastUsed = now
+		return s, nil
+	}
+	if err := makeRoom(user); err != nil {
+		database.Close()
+		return nil, err
+	}
+	s := &session{key: key, user: user, db: database, order: list.New(), byID: map[string]*list.Element{}, lastUsed: now, preparing: 1}
+	sessions[key] = s
+	return s, nil
+}
+
+// makeRoom evicts the least recently used sessions of user until another
+// one fits under MaxSessionsPerUser.
+func makeRoom(user string) error {
+	if MaxSessionsPerUser <= 0 {
+		return nil
+	}
+	for {
+		var count int
+		var victim *session
+		for _, s := range sessions {
+			if s.user != user {
+				continue
+			}
+			count++
+			if s.preparing == 0 && (victim == nil || s.lastUsed.Before(victim.lastUsed)) {
+				victim = s
+			}
+		}
+		if count < MaxSessionsPerUser {
+			return nil
+		}
+		if victim == nil {
+			return ErrTooManySessions
+		}
+		victim.close()
+	}
+}
+
+// expire closes the sessions idle for longer than SessionIdleTimeout.
+func expire(now time.Time) {
+	if SessionIdleTimeout <= 0 {
+		return
+	}
+	for _, s := range sessions {
+		if s.preparing == 0 && now.Sub(s.lastUsed) > SessionIdleTimeout {
+			s.close()
+		}
+	}
+}
+
+// Lookup returns a statement of the session and marks it recently used.
+func Lookup(key, id string) (*Statement, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	now := time.Now()
+	expire(now)
+	s, ok := sessions[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	e, ok := s.byID[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	s.order.MoveToFront(e)
+	s.lastUsed = now
+	return e.Value.(*Statement), nil
+}
+
+// Close releases a statement, and the session's database handle if it was
+// the session's last statement.
+func Close(key, id string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, ok := sessions[key]
+	if !ok {
+		return ErrNotFound
+	}
+	e, ok := s.byID[id]
+	if !ok {
+		return ErrNotFound
+	}
+	s.remove(e)
+	if s.order.Len() == 0 && s.preparing == 0 {
+		return s.close()
+	}
+	return nil
+}
+
+// CloseAll releases every statement and database handle.
+func CloseAll() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, s := range sessions {
+		s.close()
+	}
+}
+
+// close releases the session's statements and database handle. Statements
+// being prepared in it fail.
+func (s *session) close() error {
+	if s.closed {
+		return nil
+	}
+	for s.order.Len() > 0 {
+		s.remove(s.order.Front())
+	}
+	delete(sessions, s.key)
+	s.closed = true
+	return s.db.Close()
+}
+
+// remove closes the statement held by e. A statement still executing on
+// another goroutine finishes first; database/sql defers the close.
+func (s *session) remove(e *list.Element) {
+	stmt := s.order.Remove(e).(*Statement)
+	delete(s.byID, stmt.ID)
+	stmt.Stmt.Close()
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("prepared: reading random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
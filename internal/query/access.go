@@ -0,0 +1,82 @@
+package query
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+// FilterAccess classifies the columns of a filter into equality and range
+// predicates. Only conjunctions are considered: OR groups and negated nodes
+// cannot be served by a single index and are skipped.
+func FilterAccess(f *proto.Filter) (equality, ranges []string) {
+	if f == nil || f.Negate {
+		return nil, nil
+	}
+	if f.Column != "" {
+		// JSON path predicates need an expression index, not one on the column.
+		if f.Path != "" {
+			return nil, nil
+		}
+		switch f.Op {
+		case proto.Filter_EQ, proto.Filter_IN, proto.Filter_IS_NULL:
+			return []string{f.Column}, nil
+		case proto.Filter_LT, proto.Filter_LTE, proto.Filter_GT, proto.Filter_GTE, proto.Filter_BETWEEN, proto.Filter_LIKE:
+			return nil, []string{f.Column}
+		}
+		return nil, nil
+	}
+	if f.Any {
+		return nil, nil
+	}
+	for _, child := range f.Filters {
+		eq, rg := FilterAccess(child)
+		equality = appendUnique(equality, eq...)
+		ranges = appendUnique(ranges, rg...)
+	}
+	return equality, ranges
+}
+
+// conditionTermPattern finds "column <op>" pairs in a raw SQL condition.
+var conditionTermPattern = regexp.MustCompile(`(?i)"?([A-Za-z_][A-Za-z0-9_]*)"?\s*(=|==|!=|<>|<=|>=|<|>|\bIN\b|\bLIKE\b|\bBETWEEN\b|\bIS\b)`)
+
+// orPattern detects disjunctions, which make raw conditions unsuitable for index advice.
+var orPattern = regexp.MustCompile(`(?i)\bOR\b`)
+
+// ConditionAccess extracts column usage from a raw SQL condition on a best
+// effort basis. Only names that are real columns of the schema are returned.
+func ConditionAccess(condition string, schema *Schema) (equality, ranges []string) {
+	if orPattern.MatchString(condition) {
+		return nil, nil
+	}
+	for _, m := range conditionTermPattern.FindAllStringSubmatch(condition, -1) {
+		col, ok := schema.Column(m[1])
+		if !ok {
+			continue
+		}
+		switch strings.ToUpper(m[2]) {
+		case "=", "==", "IN", "IS":
+			equality = appendUnique(equality, col.Name)
+		case "<", "<=", ">", ">=", "BETWEEN", "LIKE":
+			ranges = appendUnique(ranges, col.Name)
+		}
+	}
+	return equality, ranges
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if strings.EqualFold(existing, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,181 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+// aggregateFunctions maps the API functions to their SQL names.
+var aggregateFunctions = map[proto.Aggregate_Function]string{
+	proto.Aggregate_COUNT:        "COUNT",
+	proto.Aggregate_SUM:          "SUM",
+	proto.Aggregate_AVG:          "AVG",
+	proto.Aggregate_MIN:          "MIN",
+	proto.Aggregate_MAX:          "MAX",
+	proto.Aggregate_GROUP_CONCAT: "GROUP_CONCAT",
+}
+
+// AggregateStatement is a compiled aggregate query.
+type AggregateStatement struct {
+	Statement
+	// Columns are the result column names in select order.
+	Columns []string
+}
+
+// groupScope resolves the references allowed after grouping: group-by
+// columns and aggregate aliases.
+type groupScope struct {
+	table   string
+	columns map[string]Reference
+}
+
+// Resolve implements Scope.
+func (g groupScope) Resolve(ref string) (Reference, error) {
+	if err := ValidateIdentifier(ref); err != nil {
+		return Reference{}, err
+	}
+	r, ok := g.columns[strings.ToLower(ref)]
+	if !ok {
+		return Reference{}, invalid("%q is neither a group-by column nor an aggregate alias of table %q", ref, g.table)
+	}
+	return r, nil
+}
+
+// BuildAggregate compiles an AggregateQuery request into a grouped SELECT.
+// The filter applies to rows, the having filter to groups; ordering and the
+// having filter may only reference group-by columns and aggregate aliases.
+func BuildAggregate(d db.Execer, req *proto.AggregateQueryRequest) (*AggregateStatement, error) {
+	schema, err := LoadSchema(d, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	scope := TableScope{Schema: schema}
+	if len(req.Aggregates) == 0 && len(req.GroupBy) == 0 {
+		return nil, invalid("at least one aggregate or group-by column is required")
+	}
+
+	groups := groupScope{table: schema.Table, columns: map[string]Reference{}}
+	var selects, groupBy, names []string
+
+	for _, name := range req.GroupBy {
+		ref, err := scope.Resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		col, _ := schema.Column(name)
+		if _, dup := groups.columns[strings.ToLower(col.Name)]; dup {
+			return nil, invalid("duplicate group-by column %q", name)
+		}
+		groups.columns[strings.ToLower(col.Name)] = ref
+		groupBy = append(groupBy, ref.SQL)
+		selects = append(selects, ref.SQL)
+		names = append(names, col.Name)
+	}
+
+	b := &Builder{}
+	var aggregates []string
+	for _, agg := range req.Aggregates {
+		expr, alias, err := compileAggregate(agg, scope, b)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := groups.columns[strings.ToLower(alias)]; dup {
+			return nil, invalid("duplicate result column %q", alias)
+		}
+		// SQLite prefers table columns over aliases in HAVING, so a shadowing
+		// alias would silently filter on the wrong value.
+		if _, ok := schema.Column(alias); ok {
+			return nil, invalid("alias %q conflicts with a column of table %q", alias, schema.Table)
+		}
+		groups.columns[strings.ToLower(alias)] = Reference{SQL: Quote(alias), Numeric: agg.Function != proto.Aggregate_GROUP_CONCAT}
+		aggregates = append(aggregates, expr+" AS "+Quote(alias))
+		names = append(names, alias)
+	}
+	selects = append(selects, aggregates...)
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), Quote(schema.Table))
+	if req.Filter != nil {
+		where, err := CompileFilter(req.Filter, scope, b)
+		if err != nil {
+			return nil, err
+		}
+		query += " WHERE " + where
+	}
+	if len(groupBy) > 0 {
+		query += " GROUP BY " + strings.Join(groupBy, ", ")
+	}
+	if req.Having != nil {
+		having, err := CompileFilter(req.Having, groups, b)
+		if err != nil {
+			return nil, err
+		}
+		query += " HAVING " + having
+	}
+	order, err := CompileOrderBy(req.OrderBy, groups)
+	if err != nil {
+		return nil, err
+	}
+	query += order
+
+	limit, err := RowLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	query += limitClause(limit, 0)
+
+	return &AggregateStatement{
+		Statement: Statement{SQL: query, Args: b.Args, Table: schema.Table},
+		Columns:   names,
+	}, nil
+}
+
+// compileAggregate renders one aggregate call and returns it with its alias.
+func compileAggregate(agg *proto.Aggregate, scope Scope, b *Builder) (string, string, error) {
+	fn, ok := aggregateFunctions[agg.Function]
+	if !ok {
+		return "", "", invalid("unsupported aggregate function %v", agg.Function)
+	}
+
+	var arg string
+	switch {
+	case agg.Column != "":
+		ref, err := scope.Resolve(agg.Column)
+		if err != nil {
+			return "", "", err
+		}
+		arg = ref.SQL
+	case agg.Function == proto.Aggregate_COUNT && !agg.Distinct:
+		arg = "*"
+	default:
+		return "", "", invalid("%s requires a column", fn)
+	}
+
+	if agg.Distinct {
+		arg = "DISTINCT " + arg
+	}
+	if agg.Separator != "" {
+		if agg.Function != proto.Aggregate_GROUP_CONCAT {
+			return "", "", invalid("separator is only valid for GROUP_CONCAT")
+		}
+		// SQLite does not accept a separator together with DISTINCT.
+		if agg.Distinct {
+			return "", "", invalid("GROUP_CONCAT cannot combine DISTINCT with a separator")
+		}
+		arg += ", " + b.Value(agg.Separator)
+	}
+
+	alias := agg.Alias
+	if alias == "" {
+		alias = strings.ToLower(fn)
+		if agg.Column != "" {
+			alias += "_" + agg.Column
+		}
+	}
+	if err := ValidateIdentifier(alias); err != nil {
+		return "", "", err
+	}
+	return fn + "(" + arg + ")", alias, nil
+}
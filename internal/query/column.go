@@ -0,0 +1,278 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// columnTypes are the type names accepted in column declarations.
+var columnTypes = map[string]bool{
+	"INTEGER": true, "INT": true, "BIGINT": true, "SMALLINT": true, "TINYINT": true,
+	"REAL": true, "DOUBLE": true, "FLOAT": true, "NUMERIC": true, "DECIMAL": true, "BOOLEAN": true,
+	"TEXT": true, "VARCHAR": true, "CHAR": true, "CLOB": true, "BLOB": true,
+	"DATE": true, "DATETIME": true, "TIMESTAMP": true,
+	JSONType: true,
+}
+
+// defaultKeywords are the keyword values accepted after DEFAULT.
+var defaultKeywords = map[string]bool{
+	"NULL": true, "TRUE": true, "FALSE": true,
+	"CURRENT_TIMESTAMP": true, "CURRENT_DATE": true, "CURRENT_TIME": true,
+}
+
+var foreignKeyActions = map[string]bool{
+	"CASCADE": true, "RESTRICT": true, "SET NULL": true, "SET DEFAULT": true, "NO ACTION": true,
+}
+
+// declarationToken matches one token of a column declaration: a word, a
+// number, a string literal or punctuation.
+var declarationToken = regexp.MustCompile(`^(?:[A-Za-z_][A-Za-z0-9_]*|[0-9]+(?:\.[0-9]*)?(?:[eE][+-]?[0-9]+)?|'(?:[^']|'')*'|[(),+-])`)
+
+// ColumnDefinition validates a column declaration for CREATE TABLE or ALTER
+// TABLE ADD COLUMN and renders it with the column name quoted. declared is a
+// type name from columnTypes, optionally sized as in VARCHAR(255), followed
+// by any of PRIMARY KEY [ASC|DESC] [AUTOINCREMENT], NOT NULL, NULL, UNIQUE,
+// DEFAULT with a literal, COLLATE and REFERENCES table [(column)] with ON
+// DELETE/ON UPDATE actions.
+//
+// JSON columns are stored with TEXT affinity, so that SQLite keeps documents
+// as written instead of converting number text such as 1.50, and get a CHECK
+// constraint that rejects values that are not well-formed JSON.
+func ColumnDefinition(name, declared string) (string, error) {
+	if err := ValidateIdentifier(name); err != nil {
+		return "", err
+	}
+	tokens, err := tokenizeDeclaration(declared)
+	if err != nil {
+		return "", invalid("column %q: %v", name, err)
+	}
+	p := &declarationParser{tokens: tokens}
+	def, err := p.parse(Quote(name))
+	if err != nil {
+		return "", invalid("column %q: %v", name, err)
+	}
+	return def, nil
+}
+
+func tokenizeDeclaration(declared string) ([]string, error) {
+	var tokens []string
+	rest := strings.TrimSpace(declared)
+	for rest != "" {
+		tok := declarationToken.FindString(rest)
+		if tok == "" {
+			return nil, fmt.Errorf("unexpected %q in type", rest)
+		}
+		tokens = append(tokens, tok)
+		rest = strings.TrimSpace(rest[len(tok):])
+	}
+	return tokens, nil
+}
+
+type declarationParser struct {
+	tokens []string
+	pos    int
+}
+
+// next returns the next token, upper-cased unless it is a string literal.
+func (p *declarationParser) next() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	if strings.HasPrefix(tok, "'") {
+		return tok
+	}
+	return strings.ToUpper(tok)
+}
+
+// identifier returns the next token as written, which must be a plain identifier.
+func (p *declarationParser) identifier() (string, error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("expected an identifier")
+	}
+	name := p.tokens[p.pos]
+	p.pos++
+	if !identifierPattern.MatchString(name) {
+		return "", fmt.Errorf("invalid identifier %q", name)
+	}
+	return name, nil
+}
+
+func (p *declarationParser) peek() string {
+	if p.pos >= len(p.tokens) {
+		return ""
+	}
+	return strings.ToUpper(p.tokens[p.pos])
+}
+
+// accept consumes the next token if it is word.
+func (p *declarationParser) accept(word string) bool {
+	if p.peek() == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *declarationParser) expect(word string) error {
+	if !p.accept(word) {
+		return fmt.Errorf("expected %s, got %q", word, p.peek())
+	}
+	return nil
+}
+
+func (p *declarationParser) parse(column string) (string, error) {
+	typeName := p.next()
+	if !columnTypes[typeName] {
+		return "", fmt.Errorf("unsupported type %q", typeName)
+	}
+	isJSON := typeName == JSONType
+	if isJSON {
+		typeName = "TEXT"
+	}
+	if p.accept("(") {
+		size, err := p.size()
+		if err != nil {
+			return "", err
+		}
+		typeName += "(" + size + ")"
+	}
+
+	parts := []string{column, typeName}
+	for p.pos < len(p.tokens) {
+		constraint, err := p.constraint()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, constraint)
+	}
+	if isJSON {
+		parts = append(parts, "CHECK (json_valid("+column+"))")
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// size reads the (n) or (n, m) after a type name, past the opening parenthesis.
+func (p *declarationParser) size() (string, error) {
+	var sizes []string
+	for {
+		n := p.next()
+		if !isDigits(n) {
+			return "", fmt.Errorf("invalid type size %q", n)
+		}
+		sizes = append(sizes, n)
+		if len(sizes) == 2 || !p.accept(",") {
+			break
+		}
+	}
+	if err := p.expect(")"); err != nil {
+		return "", err
+	}
+	return strings.Join(sizes, ", "), nil
+}
+
+func (p *declarationParser) constraint() (string, error) {
+	switch word := p.next(); word {
+	case "PRIMARY":
+		if err := p.expect("KEY"); err != nil {
+			return "", err
+		}
+		c := "PRIMARY KEY"
+		if p.accept("ASC") {
+			c += " ASC"
+		} else if p.accept("DESC") {
+			c += " DESC"
+		}
+		if p.accept("AUTOINCREMENT") {
+			c += " AUTOINCREMENT"
+		}
+		return c, nil
+	case "NOT":
+		if err := p.expect("NULL"); err != nil {
+			return "", err
+		}
+		return "NOT NULL", nil
+	case "NULL", "UNIQUE":
+		return word, nil
+	case "DEFAULT":
+		value, err := p.defaultValue()
+		if err != nil {
+			return "", err
+		}
+		return "DEFAULT " + value, nil
+	case "COLLATE":
+		collation := p.next()
+		if !collations[collation] {
+			return "", fmt.Errorf("unsupported collation %q", collation)
+		}
+		return "COLLATE " + collation, nil
+	case "REFERENCES":
+		return p.references()
+	default:
+		return "", fmt.Errorf("unsupported constraint %q", word)
+	}
+}
+
+func (p *declarationParser) defaultValue() (string, error) {
+	value := p.next()
+	sign := ""
+	if value == "-" || value == "+" {
+		sign, value = value, p.next()
+	}
+	switch {
+	case value != "" && value[0] >= '0' && value[0] <= '9':
+		return sign + value, nil
+	case sign == "" && strings.HasPrefix(value, "'"):
+		return value, nil
+	case sign == "" && defaultKeywords[value]:
+		return value, nil
+	}
+	return "", fmt.Errorf("DEFAULT must be a literal, got %q", sign+value)
+}
+
+func (p *declarationParser) references() (string, error) {
+	table, err := p.identifier()
+	if err != nil {
+		return "", err
+	}
+	c := "REFERENCES " + Quote(table)
+	if p.accept("(") {
+		column, err := p.identifier()
+		if err != nil {
+			return "", err
+		}
+		if err := p.expect(")"); err != nil {
+			return "", err
+		}
+		c += " (" + Quote(column) + ")"
+	}
+	for p.accept("ON") {
+		event := p.next()
+		if event != "DELETE" && event != "UPDATE" {
+			return "", fmt.Errorf("expected DELETE or UPDATE after ON, got %q", event)
+		}
+		action := p.next()
+		if action == "SET" || action == "NO" {
+			action += " " + p.next()
+		}
+		if !foreignKeyActions[action] {
+			return "", fmt.Errorf("unsupported foreign key action %q", action)
+		}
+		c += " ON " + event + " " + action
+	}
+	return c, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
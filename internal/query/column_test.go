@@ -0,0 +1,90 @@
+package query
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestColumnDefinition(t *testing.T) {
+	tests := []struct {
+		name, declared string
+		want           string
+	}{
+		{"id", "INTEGER PRIMARY KEY AUTOINCREMENT", `"id" INTEGER PRIMARY KEY AUTOINCREMENT`},
+		{"order", "text not null", `"order" TEXT NOT NULL`},
+		{"code", "VARCHAR(16) UNIQUE COLLATE NOCASE", `"code" VARCHAR(16) UNIQUE COLLATE NOCASE`},
+		{"price", "DECIMAL(10, 2) DEFAULT -1.5", `"price" DECIMAL(10, 2) DEFAULT -1.5`},
+		{"note", "TEXT DEFAULT 'it''s'", `"note" TEXT DEFAULT 'it''s'`},
+		{"created", "DATETIME DEFAULT CURRENT_TIMESTAMP", `"created" DATETIME DEFAULT CURRENT_TIMESTAMP`},
+		{"owner", "INTEGER REFERENCES users(id) ON DELETE SET NULL", `"owner" INTEGER REFERENCES "users" ("id") ON DELETE SET NULL`},
+		{"doc", "JSON NOT NULL", `"doc" TEXT NOT NULL CHECK (json_valid("doc"))`},
+	}
+	for _, tt := range tests {
+		got, err := ColumnDefinition(tt.name, tt.declared)
+		if err != nil {
+			t.Errorf("ColumnDefinition(%q, %q): %v", tt.name, tt.declared, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ColumnDefinition(%q, %q) = %s, want %s", tt.name, tt.declared, got, tt.want)
+		}
+	}
+}
+
+func TestColumnDefinitionRejects(t *testing.T) {
+	tests := []struct{ name, declared string }{
+		{"x) ; DROP TABLE t; --", "TEXT"},
+		{"", "TEXT"},
+		{"a", ""},
+		{"a", "GEOMETRY"},
+		{"a", "TEXT); ATTACH DATABASE 'x' AS y; --"},
+		{"a", "TEXT CHECK (1)"},
+		{"a", "TEXT GENERATED ALWAYS AS (load_extension('x'))"},
+		{"a", "TEXT DEFAULT (random())"},
+		{"a", "TEXT DEFAULT - 'x'"},
+		{"a", "TEXT COLLATE custom"},
+		{"a", "INTEGER REFERENCES \"t\""},
+		{"a", "INTEGER REFERENCES t ON DELETE DROP"},
+		{"a", "VARCHAR(1, 2, 3)"},
+		{"a", "TEXT DEFAULT 'unterminated"},
+	}
+	for _, tt := range tests {
+		if got, err := ColumnDefinition(tt.name, tt.declared); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("ColumnDefinition(%q, %q) = %q, %v; want an invalid query error", tt.name, tt.declared, got, err)
+		}
+	}
+}
+
+func TestJSONColumnKeepsDocumentText(t *testing.T) {
+	database, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer database.Close()
+
+	def, err := ColumnDefinition("doc", JSONType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := database.Exec("CREATE TABLE t (" + def + ")"); err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range []string{"1.50", "1e2", `{"a": 1.50}`} {
+		if _, err := database.Exec("INSERT INTO t (doc) VALUES (?)", doc); err != nil {
+			t.Fatalf("insert %s: %v", doc, err)
+		}
+		var got string
+		if err := database.QueryRow("SELECT doc FROM t WHERE rowid = last_insert_rowid()").Scan(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != doc {
+			t.Errorf("stored %s, read back %s", doc, got)
+		}
+	}
+	if _, err := database.Exec("INSERT INTO t (doc) VALUES ('{')"); err == nil {
+		t.Error("malformed JSON was accepted")
+	}
+}
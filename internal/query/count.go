@@ -0,0 +1,119 @@
+package query
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+// BuildCount compiles a COUNT(*) over the rows matching filter.
+func BuildCount(d db.Execer, table string, filter *proto.Filter) (*Statement, error) {
+	where, b, schema, err := compileWhere(d, table, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &Statement{SQL: "SELECT COUNT(*) FROM " + Quote(schema.Table) + where, Args: b.Args, Table: schema.Table}, nil
+}
+
+// BuildExists compiles a statement returning 1 if any row matches filter and
+// 0 otherwise. EXISTS stops at the first matching row.
+func BuildExists(d db.Execer, table string, filter *proto.Filter) (*Statement, error) {
+	where, b, schema, err := compileWhere(d, table, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &Statement{SQL: "SELECT EXISTS (SELECT 1 FROM " + Quote(schema.Table) + where + ")", Args: b.Args, Table: schema.Table}, nil
+}
+
+func compileWhere(d db.Execer, table string, filter *proto.Filter) (string, *Builder, *Schema, error) {
+	schema, err := LoadSchema(d, table)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	b := &Builder{}
+	if filter == nil {
+		return "", b, schema, nil
+	}
+	where, err := CompileFilter(filter, TableScope{Schema: schema}, b)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return " WHERE " + where, b, schema, nil
+}
+
+// EstimateCount estimates the number of rows matching filter from the
+// statistics ANALYZE stores in sqlite_stat1. Estimates are only possible
+// without a filter, or when the filter is a conjunction of single-value
+// equality tests covering the leading columns of a full index; ok is false
+// otherwise, or when the table has not been analyzed.
+func EstimateCount(database *sql.DB, table string, filter *proto.Filter) (count int64, ok bool, err error) {
+	equality, ok := equalityColumns(filter)
+	if !ok {
+		return 0, false, nil
+	}
+	if len(equality) == 0 {
+		return db.EstimatedRows(database, table)
+	}
+
+	stats, err := db.TableStats(database, table)
+	if err != nil || len(stats) == 0 {
+		return 0, false, err
+	}
+
+	indexes, err := db.ListIndexes(database, table)
+	if err != nil {
+		return 0, false, err
+	}
+	for _, idx := range indexes {
+		s := stats[strings.ToLower(idx.Name)]
+		if idx.Partial || len(idx.Columns) < len(equality) || len(s) <= len(equality) {
+			continue
+		}
+		covered := true
+		for _, col := range idx.Columns[:len(equality)] {
+			if !equality[strings.ToLower(col)] {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			// s[k] is the average number of rows sharing the first k key values.
+			return s[len(equality)], true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// equalityColumns returns the columns of a filter made only of ANDed
+// single-value equality tests.
+func equalityColumns(f *proto.Filter) (map[string]bool, bool) {
+	columns := map[string]bool{}
+	var walk func(f *proto.Filter) bool
+	walk = func(f *proto.Filter) bool {
+		if f.Negate {
+			return false
+		}
+		if f.Column != "" {
+			if f.Op != proto.Filter_EQ || f.Path != "" || f.Parameter || len(f.Values) != 1 || columns[strings.ToLower(f.Column)] {
+				return false
+			}
+			columns[strings.ToLower(f.Column)] = true
+			return true
+		}
+		if f.Any && len(f.Filters) > 1 {
+			return false
+		}
+		for _, child := range f.Filters {
+			if !walk(child) {
+				return false
+			}
+		}
+		return true
+	}
+	if f != nil && !walk(f) {
+		return nil, false
+	}
+	return columns, true
+}
@@ -0,0 +1,251 @@
+package query
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+// maxFilterDepth bounds nesting of filter groups.
+const maxFilterDepth = 16
+
+// maxFilterValues bounds the number of values in a single IN list.
+const maxFilterValues = 1000
+
+// Builder accumulates bound arguments while compiling SQL fragments.
+type Builder struct {
+	// Inline renders values as SQL literals instead of bound parameters.
+	// It is required where SQLite does not accept parameters, such as the
+	// WHERE clause of CREATE INDEX.
+	Inline bool
+	// Prepared allows filter nodes whose values are parameter names; each
+	// becomes a Parameter in Args, bound when the statement is executed.
+	Prepared bool
+	Args     []interface{}
+}
+
+// Parameter stands in for a value supplied when a prepared statement runs.
+type Parameter struct {
+	Name string
+	// Column is the column the parameter is compared against, and Type its declared type.
+	Column string
+	Type   string
+	// Numeric and JSON select how values are bound, see Reference.
+	Numeric bool
+	JSON    bool
+}
+
+// Value renders a single value as a placeholder or a literal.
+func (b *Builder) Value(v string) string {
+	if b.Inline {
+		return Literal(v)
+	}
+	b.Args = append(b.Args, v)
+	return "?"
+}
+
+// ValueFor renders a value compared against ref, binding numeric-looking
+// values as numbers when ref has no column affinity.
+func (b *Builder) ValueFor(ref Reference, v string) string {
+	if ref.JSON {
+		return b.jsonValue(v)
+	}
+	if !ref.Numeric || b.Inline || !numberPattern.MatchString(v) {
+		return b.Value(v)
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		b.Args = append(b.Args, n)
+	} else {
+		f, _ := strconv.ParseFloat(v, 64)
+		b.Args = append(b.Args, f)
+	}
+	return "?"
+}
+
+// numberPattern matches decimal numeric literals.
+var numberPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// Literal renders v as an SQL literal: numbers stay numeric, everything else
+// becomes a quoted string.
+func Literal(v string) string {
+	if numberPattern.MatchString(v) {
+		return v
+	}
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+// CompileFilter turns a structured filter into an SQL boolean expression.
+// A nil filter compiles to an empty string.
+func CompileFilter(f *proto.Filter, scope Scope, b *Builder) (string, error) {
+	if f == nil {
+		return "", nil
+	}
+	return compileFilter(f, scope, b, 0)
+}
+
+func compileFilter(f *proto.Filter, scope Scope, b *Builder, depth int) (string, error) {
+	if depth > maxFilterDepth {
+		return "", invalid("filter nested deeper than %d levels", maxFilterDepth)
+	}
+
+	var expr string
+	var err error
+	switch {
+	case len(f.Filters) > 0 && f.Column != "":
+		return "", invalid("filter cannot set both a column and nested filters")
+	case len(f.Filters) > 0:
+		expr, err = compileGroup(f, scope, b, depth)
+	case f.Column != "":
+		expr, err = compileComparison(f, scope, b)
+	default:
+		return "", invalid("filter needs a column or nested filters")
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if f.Negate {
+		return "NOT (" + expr + ")", nil
+	}
+	return expr, nil
+}
+
+func compileGroup(f *proto.Filter, scope Scope, b *Builder, depth int) (string, error) {
+	joiner := " AND "
+	if f.Any {
+		joiner = " OR "
+	}
+	parts := make([]string, 0, len(f.Filters))
+	for _, child := range f.Filters {
+		part, err := compileFilter(child, scope, b, depth+1)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "("+part+")")
+	}
+	return strings.Join(parts, joiner), nil
+}
+
+var comparisonOperators = map[proto.Filter_Operator]string{
+	proto.Filter_EQ:   "=",
+	proto.Filter_NE:   "!=",
+	proto.Filter_LT:   "<",
+	proto.Filter_LTE:  "<=",
+	proto.Filter_GT:   ">",
+	proto.Filter_GTE:  ">=",
+	proto.Filter_LIKE: "LIKE",
+}
+
+func compileComparison(f *proto.Filter, scope Scope, b *Builder) (string, error) {
+	ref, err := scope.Resolve(f.Column)
+	if err != nil {
+		return "", err
+	}
+	base := ref.SQL
+	path := f.Path
+	if path != "" {
+		if err := ValidateJSONPath(path); err != nil {
+			return "", err
+		}
+		ref = Reference{SQL: JSONExtract(ref.SQL, path), Type: ref.Type, JSON: true}
+	}
+	if f.Op == proto.Filter_ARRAY_CONTAINS {
+		// Array elements come from json_each and compare like extracted values.
+		ref.JSON = true
+	}
+	column := ref.SQL
+
+	value := func(v string) string { return b.ValueFor(ref, v) }
+	if f.Parameter {
+		if !b.Prepared {
+			return "", invalid("parameters are only allowed in prepared statements")
+		}
+		for _, name := range f.Values {
+			if err := ValidateIdentifier(name); err != nil {
+				return "", invalid("invalid parameter name %q", name)
+			}
+		}
+		value = func(name string) string {
+			b.Args = append(b.Args, Parameter{Name: name, Column: f.Column, Type: ref.Type, Numeric: ref.Numeric, JSON: ref.JSON})
+			return "?"
+		}
+	}
+
+	if op, ok := comparisonOperators[f.Op]; ok {
+		if len(f.Values) != 1 {
+			return "", invalid("operator %s on %q takes exactly one value", f.Op, f.Column)
+		}
+		return column + " " + op + " " + value(f.Values[0]), nil
+	}
+
+	switch f.Op {
+	case proto.Filter_IS_NULL, proto.Filter_IS_NOT_NULL:
+		if len(f.Values) != 0 {
+			return "", invalid("operator %s on %q takes no values", f.Op, f.Column)
+		}
+		if f.Op == proto.Filter_IS_NULL {
+			return column + " IS NULL", nil
+		}
+		return column + " IS NOT NULL", nil
+
+	case proto.Filter_BETWEEN:
+		if len(f.Values) != 2 {
+			return "", invalid("operator BETWEEN on %q takes exactly two values", f.Column)
+		}
+		return column + " BETWEEN " + value(f.Values[0]) + " AND " + value(f.Values[1]), nil
+
+	case proto.Filter_IN, proto.Filter_NOT_IN:
+		if len(f.Values) == 0 || len(f.Values) > maxFilterValues {
+			return "", invalid("operator %s on %q takes between 1 and %d values", f.Op, f.Column, maxFilterValues)
+		}
+		placeholders := make([]string, len(f.Values))
+		for i, v := range f.Values {
+			placeholders[i] = value(v)
+		}
+		op := " IN ("
+		if f.Op == proto.Filter_NOT_IN {
+			op = " NOT IN ("
+		}
+		return column + op + strings.Join(placeholders, ", ") + ")", nil
+
+	case proto.Filter_JSON_CONTAINS:
+		if len(f.Values) != 1 {
+			return "", invalid("operator JSON_CONTAINS on %q takes exactly one JSON document", f.Column)
+		}
+		if f.Parameter {
+			return "", invalid("operator JSON_CONTAINS does not accept parameters")
+		}
+		if path == "" {
+			path = "$"
+		}
+		return compileJSONContains(base, path, f.Values[0], b)
+
+	case proto.Filter_ARRAY_CONTAINS:
+		if len(f.Values) != 1 {
+			return "", invalid("operator ARRAY_CONTAINS on %q takes exactly one value", f.Column)
+		}
+		if path == "" {
+			path = "$"
+		}
+		return arrayMembership(base, path, value(f.Values[0])), nil
+	}
+
+	return "", invalid("unsupported filter operator %s", f.Op)
+}
+
+// FilterColumns returns every column referenced by a filter, in order of appearance.
+func FilterColumns(f *proto.Filter) []string {
+	if f == nil {
+		return nil
+	}
+	if f.Column != "" {
+		return []string{f.Column}
+	}
+	var columns []string
+	for _, child := range f.Filters {
+		columns = append(columns, FilterColumns(child)...)
+	}
+	return columns
+}
@@ -0,0 +1,104 @@
+package query
+
+import (
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+// indexFunction describes a deterministic SQLite function allowed in index
+// expressions. Arity counts the column as the first argument.
+type indexFunction struct {
+	minArgs, maxArgs int
+}
+
+// indexFunctions is the whitelist of functions usable in expression indexes.
+var indexFunctions = map[string]indexFunction{
+	"lower":     {1, 1},
+	"upper":     {1, 1},
+	"length":    {1, 1},
+	"abs":       {1, 1},
+	"trim":      {1, 2},
+	"ltrim":     {1, 2},
+	"rtrim":     {1, 2},
+	"substr":    {2, 3},
+	"round":     {1, 2},
+	"ifnull":    {2, 2},
+	"coalesce":  {2, 8},
+	"date":      {1, 1},
+	"datetime":  {1, 1},
+	"julianday": {1, 1},
+	"unicode":   {1, 1},
+	"hex":       {1, 1},
+}
+
+// collations are the built-in SQLite collating sequences.
+var collations = map[string]bool{
+	"BINARY": true,
+	"NOCASE": true,
+	"RTRIM":  true,
+}
+
+// CompileIndexKey renders one index key: the column or function expression
+// followed by its optional collation and sort order.
+func CompileIndexKey(key *proto.IndexColumn, scope Scope) (string, error) {
+	ref, err := scope.Resolve(key.Column)
+	if err != nil {
+		return "", err
+	}
+	column := ref.SQL
+	if key.Path != "" {
+		if err := ValidateJSONPath(key.Path); err != nil {
+			return "", err
+		}
+		column = JSONExtract(column, key.Path)
+	}
+
+	expr := column
+	if key.Function != "" {
+		name := strings.ToLower(key.Function)
+		fn, ok := indexFunctions[name]
+		if !ok {
+			return "", invalid("function %q is not allowed in an index expression", key.Function)
+		}
+		argc := 1 + len(key.Args)
+		if argc < fn.minArgs || argc > fn.maxArgs {
+			return "", invalid("function %s takes %d to %d arguments including the column", name, fn.minArgs, fn.maxArgs)
+		}
+		args := []string{column}
+		for _, a := range key.Args {
+			args = append(args, Literal(a))
+		}
+		expr = name + "(" + strings.Join(args, ", ") + ")"
+	} else if len(key.Args) > 0 {
+		return "", invalid("arguments given for column %q without a function", key.Column)
+	}
+
+	if key.Collation != "" {
+		collation := strings.ToUpper(key.Collation)
+		if !collations[collation] {
+			return "", invalid("unknown collation %q", key.Collation)
+		}
+		expr += " COLLATE " + collation
+	}
+	if key.Descending {
+		expr += " DESC"
+	}
+	return expr, nil
+}
+
+// IndexKeyName returns a short name fragment for a key, used to derive index names.
+func IndexKeyName(key *proto.IndexColumn) string {
+	name := key.Column
+	if key.Path != "" {
+		// "$.address.city" -> "address_city"
+		steps := strings.FieldsFunc(key.Path, func(r rune) bool { return r == '$' || r == '.' || r == '[' || r == ']' })
+		if len(steps) > 0 {
+			name += "_" + strings.Join(steps, "_")
+		}
+	}
+	if key.Function != "" {
+		return strings.ToLower(key.Function) + "_" + name
+	}
+	return name
+}
@@ -0,0 +1,302 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+// maxJoins bounds the number of tables joined in one query.
+const maxJoins = 16
+
+// joinTypes maps the API join types to SQL.
+var joinTypes = map[proto.Join_Type]string{
+	proto.Join_INNER: "JOIN",
+	proto.Join_LEFT:  "LEFT JOIN",
+}
+
+// JoinColumn describes one column of a join result.
+type JoinColumn struct {
+	// Table is the alias of the table the column comes from.
+	Table  string
+	Column db.ColumnInfo
+}
+
+// Name returns the qualified result name, "alias.column".
+func (c JoinColumn) Name() string {
+	return c.Table + "." + c.Column.Name
+}
+
+// JoinStatement is a compiled join query.
+type JoinStatement struct {
+	Statement
+	// Columns are the result columns in select order.
+	Columns []JoinColumn
+}
+
+// joinTable is a table participating in a join under an alias.
+type joinTable struct {
+	alias  string
+	schema *Schema
+}
+
+// JoinScope resolves "alias.column" references, and bare column names that
+// are unique across the joined tables.
+type JoinScope struct {
+	tables []joinTable
+}
+
+// Resolve implements Scope.
+func (j *JoinScope) Resolve(ref string) (Reference, error) {
+	t, col, err := j.lookup(ref)
+	if err != nil {
+		return Reference{}, err
+	}
+	return Reference{SQL: Quote(t.alias) + "." + Quote(col.Name), Type: col.Type}, nil
+}
+
+func (j *JoinScope) lookup(ref string) (joinTable, db.ColumnInfo, error) {
+	if alias, name, ok := strings.Cut(ref, "."); ok {
+		t, ok := j.table(alias)
+		if !ok {
+			return joinTable{}, db.ColumnInfo{}, invalid("unknown table alias %q", alias)
+		}
+		if err := ValidateIdentifier(name); err != nil {
+			return joinTable{}, db.ColumnInfo{}, err
+		}
+		col, ok := t.schema.Column(name)
+		if !ok {
+			return joinTable{}, db.ColumnInfo{}, invalid("unknown column %q in table %q", name, t.alias)
+		}
+		return t, col, nil
+	}
+
+	if err := ValidateIdentifier(ref); err != nil {
+		return joinTable{}, db.ColumnInfo{}, err
+	}
+	var found []joinTable
+	var column db.ColumnInfo
+	for _, t := range j.tables {
+		if col, ok := t.schema.Column(ref); ok {
+			found = append(found, t)
+			column = col
+		}
+	}
+	switch len(found) {
+	case 0:
+		return joinTable{}, db.ColumnInfo{}, invalid("unknown column %q", ref)
+	case 1:
+		return found[0], column, nil
+	}
+	return joinTable{}, db.ColumnInfo{}, invalid("column %q is ambiguous; qualify it with a table alias", ref)
+}
+
+func (j *JoinScope) table(alias string) (joinTable, bool) {
+	for _, t := range j.tables {
+		if strings.EqualFold(t.alias, alias) {
+			return t, true
+		}
+	}
+	return joinTable{}, false
+}
+
+// add loads a table and registers it under its alias.
+func (j *JoinScope) add(d db.Execer, ref *proto.TableRef) (joinTable, error) {
+	if ref == nil {
+		return joinTable{}, invalid("table is required")
+	}
+	schema, err := LoadSchema(d, ref.TableName)
+	if err != nil {
+		return joinTable{}, err
+	}
+	alias := ref.Alias
+	if alias == "" {
+		alias = schema.Table
+	}
+	if err := ValidateIdentifier(alias); err != nil {
+		return joinTable{}, err
+	}
+	if _, dup := j.table(alias); dup {
+		return joinTable{}, invalid("duplicate table alias %q; alias tables joined more than once", alias)
+	}
+	t := joinTable{alias: alias, schema: schema}
+	j.tables = append(j.tables, t)
+	return t, nil
+}
+
+// BuildJoin compiles a JoinQuery request into a SELECT over joined tables.
+// Join conditions come from explicit key pairs or, when none are given, from
+// the single foreign key declared between the new table and an earlier one.
+func BuildJoin(d db.Execer, req *proto.JoinQueryRequest) (*JoinStatement, error) {
+	if len(req.Joins) > maxJoins {
+		return nil, invalid("at most %d joins are allowed", maxJoins)
+	}
+
+	scope := &JoinScope{}
+	from, err := scope.add(d, req.From)
+	if err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(" FROM %s AS %s", Quote(from.schema.Table), Quote(from.alias))
+
+	for _, join := range req.Joins {
+		kind, ok := joinTypes[join.Type]
+		if !ok {
+			return nil, invalid("unsupported join type %v", join.Type)
+		}
+		t, err := scope.add(d, join.Table)
+		if err != nil {
+			return nil, err
+		}
+		on, err := joinCondition(d, scope, t, join.On)
+		if err != nil {
+			return nil, err
+		}
+		query += fmt.Sprintf(" %s %s AS %s ON %s", kind, Quote(t.schema.Table), Quote(t.alias), on)
+	}
+
+	columns, err := joinProjection(scope, req.Columns)
+	if err != nil {
+		return nil, err
+	}
+	selects := make([]string, len(columns))
+	for i, c := range columns {
+		selects[i] = Quote(c.Table) + "." + Quote(c.Column.Name)
+	}
+	query = "SELECT " + strings.Join(selects, ", ") + query
+
+	b := &Builder{}
+	if req.Filter != nil {
+		where, err := CompileFilter(req.Filter, scope, b)
+		if err != nil {
+			return nil, err
+		}
+		query += " WHERE " + where
+	}
+	order, err := CompileOrderBy(req.OrderBy, scope)
+	if err != nil {
+		return nil, err
+	}
+	query += order
+
+	limit, err := RowLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	query += limitClause(limit, 0)
+
+	return &JoinStatement{
+		Statement: Statement{SQL: query, Args: b.Args, Table: from.schema.Table},
+		Columns:   columns,
+	}, nil
+}
+
+// joinCondition renders the ON clause joining t to the tables before it.
+func joinCondition(d db.Execer, scope *JoinScope, t joinTable, keys []*proto.JoinKey) (string, error) {
+	if len(keys) == 0 {
+		return declaredJoinCondition(d, scope, t)
+	}
+
+	earlier := &JoinScope{tables: scope.tables[:len(scope.tables)-1]}
+	current := &JoinScope{tables: []joinTable{t}}
+	var terms []string
+	for _, key := range keys {
+		left, err := earlier.Resolve(key.Left)
+		if err != nil {
+			return "", err
+		}
+		right, err := current.Resolve(key.Right)
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, left.SQL+" = "+right.SQL)
+	}
+	return strings.Join(terms, " AND "), nil
+}
+
+// declaredJoinCondition finds the foreign key linking t to exactly one of
+// the earlier tables, in either direction.
+func declaredJoinCondition(d db.Execer, scope *JoinScope, t joinTable) (string, error) {
+	var matches []string
+	add := func(child, parent joinTable, fk db.ForeignKey) {
+		terms := make([]string, len(fk.From))
+		for i := range fk.From {
+			terms[i] = Quote(child.alias) + "." + Quote(fk.From[i]) + " = " + Quote(parent.alias) + "." + Quote(fk.To[i])
+		}
+		matches = append(matches, strings.Join(terms, " AND "))
+	}
+
+	ownKeys, err := db.ForeignKeys(d, t.schema.Table)
+	if err != nil {
+		return "", err
+	}
+	for _, other := range scope.tables[:len(scope.tables)-1] {
+		for _, fk := range ownKeys {
+			if strings.EqualFold(fk.Table, other.schema.Table) {
+				add(t, other, fk)
+			}
+		}
+		// A self-join sees its own foreign keys already; don't count them twice.
+		if strings.EqualFold(other.schema.Table, t.schema.Table) {
+			continue
+		}
+		otherKeys, err := db.ForeignKeys(d, other.schema.Table)
+		if err != nil {
+			return "", err
+		}
+		for _, fk := range otherKeys {
+			if strings.EqualFold(fk.Table, t.schema.Table) {
+				add(other, t, fk)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", invalid("no foreign key links %q to the tables before it; give explicit join keys", t.alias)
+	case 1:
+		return matches[0], nil
+	}
+	return "", invalid("several foreign keys link %q to the tables before it; give explicit join keys", t.alias)
+}
+
+// joinProjection expands the requested columns, defaulting to every column
+// of every joined table.
+func joinProjection(scope *JoinScope, refs []string) ([]JoinColumn, error) {
+	if len(refs) == 0 {
+		refs = make([]string, len(scope.tables))
+		for i, t := range scope.tables {
+			refs[i] = t.alias + ".*"
+		}
+	}
+
+	var columns []JoinColumn
+	seen := map[string]bool{}
+	appendColumn := func(t joinTable, col db.ColumnInfo) {
+		c := JoinColumn{Table: t.alias, Column: col}
+		if !seen[strings.ToLower(c.Name())] {
+			seen[strings.ToLower(c.Name())] = true
+			columns = append(columns, c)
+		}
+	}
+	for _, ref := range refs {
+		if alias, ok := strings.CutSuffix(ref, ".*"); ok {
+			t, ok := scope.table(alias)
+			if !ok {
+				return nil, invalid("unknown table alias %q", alias)
+			}
+			for _, col := range t.schema.Columns {
+				appendColumn(t, col)
+			}
+			continue
+		}
+		t, col, err := scope.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		appendColumn(t, col)
+	}
+	return columns, nil
+}
@@ -0,0 +1,162 @@
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONType is the type name that declares a JSON column, see ColumnDefinition.
+const JSONType = "JSON"
+
+// jsonPathPattern accepts the subset of SQLite JSON paths made of object
+// keys and array indexes, e.g. "$.address.city" or "$.tags[0]".
+var jsonPathPattern = regexp.MustCompile(`^\$(\.[A-Za-z_][A-Za-z0-9_]*|\[[0-9]+\])*$`)
+
+// maxContainmentTerms bounds the comparisons a JSON_CONTAINS pattern expands to.
+const maxContainmentTerms = 64
+
+// ValidateJSONPath checks a JSON path against the supported syntax.
+func ValidateJSONPath(path string) error {
+	if !jsonPathPattern.MatchString(path) {
+		return invalid("invalid JSON path %q; use $, .key and [index] steps", path)
+	}
+	return nil
+}
+
+// JSONExtract renders the extraction of path from a JSON column expression.
+// The path is inlined rather than bound so that indexes on the same
+// expression can be used.
+func JSONExtract(column, path string) string {
+	return "json_extract(" + column + ", " + Literal(path) + ")"
+}
+
+// jsonScalar converts a filter value compared against extracted JSON into
+// the SQL value json_extract would produce: JSON numbers become numbers,
+// booleans 1 or 0, quoted strings their content. Anything else, including
+// numbers with leading zeros, is taken as a plain string.
+func jsonScalar(v string) interface{} {
+	dec := json.NewDecoder(strings.NewReader(v))
+	dec.UseNumber()
+	var parsed interface{}
+	if err := dec.Decode(&parsed); err != nil || dec.More() {
+		return v
+	}
+	switch p := parsed.(type) {
+	case json.Number:
+		if n, err := p.Int64(); err == nil {
+			return n
+		}
+		if f, err := p.Float64(); err == nil {
+			return f
+		}
+	case bool:
+		if p {
+			return int64(1)
+		}
+		return int64(0)
+	case string:
+		return p
+	case nil:
+		return nil
+	}
+	return v
+}
+
+// sqlLiteral renders a value produced by jsonScalar as an SQL literal.
+func sqlLiteral(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+	}
+	return "NULL"
+}
+
+// compileJSONContains expands a containment pattern into comparisons: every
+// key of a pattern object must match recursively, every element of a pattern
+// array must be an element of the target array, and scalars must be equal.
+func compileJSONContains(column, path, pattern string, b *Builder) (string, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(pattern)))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil || dec.More() {
+		return "", invalid("JSON_CONTAINS needs a JSON document, got %q", pattern)
+	}
+	var terms []string
+	if err := containmentTerms(column, path, doc, b, &terms); err != nil {
+		return "", err
+	}
+	if len(terms) == 0 {
+		// An empty object or array is contained in every document of that type.
+		kind := "object"
+		if _, ok := doc.([]interface{}); ok {
+			kind = "array"
+		}
+		return "json_type(" + column + ", " + Literal(path) + ") = '" + kind + "'", nil
+	}
+	return strings.Join(terms, " AND "), nil
+}
+
+func containmentTerms(column, path string, doc interface{}, b *Builder, terms *[]string) error {
+	if len(*terms) >= maxContainmentTerms {
+		return invalid("JSON_CONTAINS pattern expands to more than %d comparisons", maxContainmentTerms)
+	}
+	switch d := doc.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := path + "." + k
+			if err := ValidateJSONPath(child); err != nil {
+				return invalid("unsupported key %q in JSON_CONTAINS pattern", k)
+			}
+			if err := containmentTerms(column, child, d[k], b, terms); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, elem := range d {
+			switch elem.(type) {
+			case map[string]interface{}, []interface{}:
+				return invalid("array elements in JSON_CONTAINS patterns must be scalars")
+			}
+			*terms = append(*terms, arrayMembership(column, path, b.jsonValue(scalarText(elem))))
+		}
+	default:
+		*terms = append(*terms, JSONExtract(column, path)+" = "+b.jsonValue(scalarText(d)))
+	}
+	return nil
+}
+
+// scalarText renders a decoded JSON scalar back as JSON text for jsonScalar.
+func scalarText(v interface{}) string {
+	text, _ := json.Marshal(v)
+	return string(text)
+}
+
+// arrayMembership renders a test that the array at path contains value.
+func arrayMembership(column, path, value string) string {
+	return "EXISTS (SELECT 1 FROM json_each(" + column + ", " + Literal(path) + ") WHERE json_each.value = " + value + ")"
+}
+
+// jsonValue renders a value compared against extracted JSON.
+func (b *Builder) jsonValue(v string) string {
+	scalar := jsonScalar(v)
+	if b.Inline {
+		return sqlLiteral(scalar)
+	}
+	b.Args = append(b.Args, scalar)
+	return "?"
+}
@@ -0,0 +1,69 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+// MaxRows caps the number of rows a single query returns, whether or not the
+// client asks for a limit. Zero disables the cap.
+var MaxRows int64 = 10000
+
+// nullsOrder maps the API null placement to SQL.
+var nullsOrder = map[proto.OrderBy_Nulls]string{
+	proto.OrderBy_NULLS_DEFAULT: "",
+	proto.OrderBy_NULLS_FIRST:   " NULLS FIRST",
+	proto.OrderBy_NULLS_LAST:    " NULLS LAST",
+}
+
+// CompileOrderBy renders an ORDER BY clause, including the leading space, or
+// an empty string when there are no sort keys.
+func CompileOrderBy(orders []*proto.OrderBy, scope Scope) (string, error) {
+	if len(orders) == 0 {
+		return "", nil
+	}
+	keys := make([]string, len(orders))
+	for i, o := range orders {
+		ref, err := scope.Resolve(o.Column)
+		if err != nil {
+			return "", err
+		}
+		nulls, ok := nullsOrder[o.Nulls]
+		if !ok {
+			return "", invalid("unsupported null ordering %v", o.Nulls)
+		}
+		keys[i] = ref.SQL
+		if o.Descending {
+			keys[i] += " DESC"
+		}
+		keys[i] += nulls
+	}
+	return " ORDER BY " + strings.Join(keys, ", "), nil
+}
+
+// RowLimit returns the number of rows to fetch for a requested limit, applying
+// MaxRows. Zero means no limit at all.
+func RowLimit(requested int64) (int64, error) {
+	if requested < 0 {
+		return 0, invalid("limit must not be negative")
+	}
+	if MaxRows > 0 && (requested == 0 || requested > MaxRows) {
+		return MaxRows, nil
+	}
+	return requested, nil
+}
+
+// limitClause renders LIMIT/OFFSET, including the leading space.
+func limitClause(limit, offset int64) string {
+	switch {
+	case limit > 0 && offset > 0:
+		return fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	case limit > 0:
+		return fmt.Sprintf(" LIMIT %d", limit)
+	case offset > 0:
+		return fmt.Sprintf(" LIMIT -1 OFFSET %d", offset)
+	}
+	return ""
+}
@@ -0,0 +1,111 @@
+package query
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
+)
+
+// PlanNode is one row of EXPLAIN QUERY PLAN output arranged as a tree.
+type PlanNode struct {
+	ID       int
+	Parent   int
+	Detail   string
+	Children []*PlanNode
+}
+
+// PlanStep is the table access described by a SCAN or SEARCH plan row.
+type PlanStep struct {
+	// Op is "SCAN" or "SEARCH".
+	Op    string
+	Table string
+	// Index is the index used, if any. Rowid and INTEGER PRIMARY KEY lookups
+	// report an empty index with Rowid set.
+	Index    string
+	Covering bool
+	Rowid    bool
+}
+
+// FullScan reports whether the step reads every row of the table without an index.
+func (s PlanStep) FullScan() bool {
+	return s.Op == "SCAN" && s.Index == "" && !s.Rowid
+}
+
+// Plan is the parsed result of EXPLAIN QUERY PLAN.
+type Plan struct {
+	Roots []*PlanNode
+	Steps []PlanStep
+}
+
+// FullScans returns the tables that are scanned without an index.
+func (p *Plan) FullScans() []string {
+	var tables []string
+	for _, s := range p.Steps {
+		if s.FullScan() {
+			tables = append(tables, s.Table)
+		}
+	}
+	return tables
+}
+
+// IndexesUsed returns the distinct indexes referenced by the plan.
+func (p *Plan) IndexesUsed() []string {
+	seen := map[string]bool{}
+	var indexes []string
+	for _, s := range p.Steps {
+		if s.Index != "" && !seen[s.Index] {
+			seen[s.Index] = true
+			indexes = append(indexes, s.Index)
+		}
+	}
+	return indexes
+}
+
+// Explain runs EXPLAIN QUERY PLAN for a statement without executing it.
+func Explain(d db.Execer, stmt *Statement) (*Plan, error) {
+	rows, err := d.Query("EXPLAIN QUERY PLAN "+stmt.SQL, stmt.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	plan := &Plan{}
+	nodes := map[int]*PlanNode{}
+	for rows.Next() {
+		var notUsed int
+		node := &PlanNode{}
+		if err := rows.Scan(&node.ID, &node.Parent, &notUsed, &node.Detail); err != nil {
+			return nil, err
+		}
+		nodes[node.ID] = node
+		if parent, ok := nodes[node.Parent]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			plan.Roots = append(plan.Roots, node)
+		}
+		if step, ok := ParsePlanDetail(node.Detail); ok {
+			plan.Steps = append(plan.Steps, step)
+		}
+	}
+	return plan, rows.Err()
+}
+
+var planDetailPattern = regexp.MustCompile(`^(SCAN|SEARCH) (\S+)(?: AS \S+)?(?: USING (COVERING )?INDEX (\S+))?(?: USING (INTEGER PRIMARY KEY|ROWID))?`)
+
+// ParsePlanDetail extracts the table access from a plan detail string such as
+// "SEARCH users USING INDEX users_email_idx (email=?)".
+func ParsePlanDetail(detail string) (PlanStep, bool) {
+	m := planDetailPattern.FindStringSubmatch(detail)
+	if m == nil {
+		return PlanStep{}, false
+	}
+	step := PlanStep{
+		Op:       m[1],
+		Table:    m[2],
+		Covering: m[3] != "",
+		Index:    m[4],
+		Rowid:    m[5] != "" || strings.Contains(detail, "USING INTEGER PRIMARY KEY") || strings.Contains(detail, "USING ROWID"),
+	}
+	return step, true
+}
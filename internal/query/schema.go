@@ -0,0 +1,128 @@
+// Package query compiles the structured query model of the gRPC API into
+// parameterized SQLite statements, validating every identifier on the way.
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
+)
+
+// ErrInvalidQuery is wrapped by every validation error returned from this package.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// identifierPattern matches plain SQL identifiers.
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+func invalid(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidQuery, fmt.Sprintf(format, args...))
+}
+
+// ValidateIdentifier checks that name is a plain identifier (letters, digits, underscores).
+func ValidateIdentifier(name string) error {
+	if !identifierPattern.MatchString(name) {
+		return invalid("invalid identifier %q", name)
+	}
+	return nil
+}
+
+// Quote returns the quoted form of a validated identifier.
+func Quote(name string) string {
+	return db.QuoteIdentifier(name)
+}
+
+// Schema describes the columns of a single table.
+type Schema struct {
+	Table   string
+	Columns []db.ColumnInfo
+	byName  map[string]db.ColumnInfo
+}
+
+// LoadSchema reads the column list of a user table.
+func LoadSchema(d db.Execer, table string) (*Schema, error) {
+	if err := ValidateIdentifier(table); err != nil {
+		return nil, err
+	}
+	if db.IsInternalTable(table) {
+		return nil, invalid("table %q is reserved", table)
+	}
+	if shadow, err := db.IsSearchShadowTable(d, table); err != nil {
+		return nil, err
+	} else if shadow {
+		return nil, invalid("table %q belongs to a search index", table)
+	}
+	columns, err := db.TableColumns(d, table)
+	if err != nil {
+		return nil, err
+	}
+	return NewSchema(table, columns), nil
+}
+
+// NewSchema builds a Schema from already known columns.
+func NewSchema(table string, columns []db.ColumnInfo) *Schema {
+	s := &Schema{Table: table, Columns: columns, byName: map[string]db.ColumnInfo{}}
+	for _, c := range columns {
+		s.byName[strings.ToLower(c.Name)] = c
+	}
+	return s
+}
+
+// Column looks up a column by name, case-insensitively.
+func (s *Schema) Column(name string) (db.ColumnInfo, bool) {
+	c, ok := s.byName[strings.ToLower(name)]
+	return c, ok
+}
+
+// ColumnNames returns the column names in declaration order.
+func (s *Schema) ColumnNames() []string {
+	names := make([]string, len(s.Columns))
+	for i, c := range s.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Reference is a column reference resolved to SQL.
+type Reference struct {
+	SQL string
+	// Type is the declared type of the referenced column, if known.
+	Type string
+	// Numeric marks expressions without column affinity, such as aggregate
+	// results. Values compared against them are bound as numbers when they
+	// look numeric, since SQLite would otherwise compare them as text.
+	Numeric bool
+	// JSON marks values extracted from JSON documents. Values compared
+	// against them are read as JSON scalars, see jsonScalar.
+	JSON bool
+}
+
+// Scope resolves column references appearing in a query to SQL expressions.
+type Scope interface {
+	// Resolve validates a column reference and returns its SQL form.
+	Resolve(ref string) (Reference, error)
+}
+
+// TableScope resolves bare column names against a single table.
+type TableScope struct {
+	Schema *Schema
+	// Qualify prefixes resolved columns with the quoted table name.
+	Qualify bool
+}
+
+// Resolve implements Scope.
+func (t TableScope) Resolve(ref string) (Reference, error) {
+	if err := ValidateIdentifier(ref); err != nil {
+		return Reference{}, err
+	}
+	col, ok := t.Schema.Column(ref)
+	if !ok {
+		return Reference{}, invalid("unknown column %q in table %q", ref, t.Schema.Table)
+	}
+	if t.Qualify {
+		return Reference{SQL: Quote(t.Schema.Table) + "." + Quote(col.Name), Type: col.Type}, nil
+	}
+	return Reference{SQL: Quote(col.Name), Type: col.Type}, nil
+}
@@ -0,0 +1,103 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SplitStatements splits SQL text into its statements on top-level
+// semicolons, skipping string literals, quoted identifiers and comments.
+// Semicolons inside CREATE TRIGGER bodies do not end the statement.
+// Empty statements are dropped.
+func SplitStatements(text string) ([]string, error) {
+	var statements []string
+	start := 0
+	depth := 0 // open BEGIN/CASE blocks in a trigger body
+	trigger := false
+	var word strings.Builder
+	words := 0
+
+	flushWord := func() {
+		if word.Len() == 0 {
+			return
+		}
+		w := strings.ToUpper(word.String())
+		word.Reset()
+		words++
+		switch {
+		case w == "TRIGGER" && words <= 4:
+			// CREATE [TEMP|TEMPORARY] TRIGGER
+			trigger = true
+		case trigger && (w == "BEGIN" || w == "CASE"):
+			depth++
+		case trigger && w == "END" && depth > 0:
+			depth--
+		}
+	}
+	end := func(i int) {
+		flushWord()
+		if stmt := strings.TrimSpace(text[start:i]); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		start = i + 1
+		depth, trigger, words = 0, false, 0
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			flushWord()
+			closer := c
+			if c == '[' {
+				closer = ']'
+			}
+			j := strings.IndexByte(text[i+1:], closer)
+			if j < 0 {
+				return nil, invalid("unterminated quote starting at offset %d", i)
+			}
+			// A doubled quote is an escaped quote and simply continues the literal.
+			i += j + 1
+		case c == '-' && i+1 < len(text) && text[i+1] == '-':
+			flushWord()
+			j := strings.IndexByte(text[i:], '\n')
+			if j < 0 {
+				i = len(text)
+			} else {
+				i += j
+			}
+		case c == '/' && i+1 < len(text) && text[i+1] == '*':
+			flushWord()
+			j := strings.Index(text[i+2:], "*/")
+			if j < 0 {
+				return nil, invalid("unterminated comment starting at offset %d", i)
+			}
+			i += j + 3
+		case c == ';':
+			flushWord()
+			if depth == 0 {
+				end(i)
+			}
+		case c == '_' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)):
+			word.WriteByte(c)
+		default:
+			flushWord()
+		}
+	}
+	end(len(text))
+	return statements, nil
+}
+
+// singleStatement rejects statement text that holds more than one
+// statement. Legacy raw conditions are spliced into the statements built
+// here and must not end them and start others.
+func singleStatement(text string) error {
+	statements, err := SplitStatements(text)
+	if err != nil {
+		return err
+	}
+	if len(statements) != 1 {
+		return invalid("condition must not end the statement")
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+// searchTokenizers are the FTS5 tokenizer configurations accepted by
+// CreateSearchIndex.
+var searchTokenizers = map[string]bool{
+	"unicode61":                     true,
+	"unicode61 remove_diacritics 2": true,
+	"ascii":                         true,
+	"porter":                        true,
+	"porter unicode61":              true,
+	"porter ascii":                  true,
+	"trigram":                       true,
+}
+
+// maxSnippetTokens bounds the snippet length FTS5 accepts.
+const maxSnippetTokens = 64
+
+// SearchIndexStatements returns the statements that create an external-
+// content FTS5 index over columns of a table, the triggers keeping it in
+// sync, and the initial rebuild from the table's current rows.
+func SearchIndexStatements(schema *Schema, name string, columns []string, tokenizer string) ([]string, error) {
+	if err := ValidateIdentifier(name); err != nil {
+		return nil, err
+	}
+	if len(columns) == 0 {
+		return nil, invalid("at least one column is required")
+	}
+	if tokenizer == "" {
+		tokenizer = "unicode61"
+	}
+	if !searchTokenizers[strings.ToLower(tokenizer)] {
+		return nil, invalid("unsupported tokenizer %q", tokenizer)
+	}
+
+	scope := TableScope{Schema: schema}
+	cols := make([]string, len(columns))
+	newCols := make([]string, len(columns))
+	oldCols := make([]string, len(columns))
+	for i, c := range columns {
+		ref, err := scope.Resolve(c)
+		if err != nil {
+			return nil, err
+		}
+		cols[i] = ref.SQL
+		newCols[i] = "new." + ref.SQL
+		oldCols[i] = "old." + ref.SQL
+	}
+
+	index, table := Quote(name), Quote(schema.Table)
+	list := strings.Join(cols, ", ")
+	insert := fmt.Sprintf("INSERT INTO %s(rowid, %s) VALUES (new.rowid, %s);", index, list, strings.Join(newCols, ", "))
+	remove := fmt.Sprintf("INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.rowid, %s);", index, index, list, strings.Join(oldCols, ", "))
+	triggers := db.SearchTriggerNames(name)
+
+	return []string{
+		fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(%s, content=%s, content_rowid='rowid', tokenize=%s)",
+			index, list, Literal(schema.Table), Literal(strings.ToLower(tokenizer))),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT ON %s BEGIN %s END", Quote(triggers[0]), table, insert),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER DELETE ON %s BEGIN %s END", Quote(triggers[1]), table, remove),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER UPDATE ON %s BEGIN %s %s END", Quote(triggers[2]), table, remove, insert),
+		fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", index, index),
+	}, nil
+}
+
+// SearchStatement is a compiled full-text search.
+type SearchStatement struct {
+	Statement
+	// Columns are the table columns returned for each hit, followed in the
+	// result set by the rank and the snippet.
+	Columns []string
+}
+
+// BuildSearch compiles a Search request against a search index. Hits are
+// ordered by FTS5 rank (best first) and carry a highlighted snippet.
+func BuildSearch(index db.SearchIndexInfo, schema *Schema, req *proto.SearchRequest) (*SearchStatement, error) {
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, invalid("query is required")
+	}
+	if req.Offset < 0 {
+		return nil, invalid("offset must not be negative")
+	}
+	limit, err := RowLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+	tokens := int64(req.SnippetTokens)
+	switch {
+	case tokens == 0:
+		tokens = 16
+	case tokens < 0 || tokens > maxSnippetTokens:
+		return nil, invalid("snippet_tokens must be between 1 and %d", maxSnippetTokens)
+	}
+	start, end := req.HighlightStart, req.HighlightEnd
+	if start == "" && end == "" {
+		start, end = "<b>", "</b>"
+	}
+
+	scope := TableScope{Schema: schema, Qualify: true}
+	names := req.Columns
+	if len(names) == 0 {
+		names = schema.ColumnNames()
+	}
+	columns := make([]string, len(names))
+	selects := make([]string, len(names))
+	for i, name := range names {
+		ref, err := scope.Resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		col, _ := schema.Column(name)
+		columns[i] = col.Name
+		selects[i] = ref.SQL
+	}
+
+	b := &Builder{}
+	idx := Quote(index.Name)
+	selects = append(selects, idx+".rank",
+		fmt.Sprintf("snippet(%s, -1, %s, %s, %s, %d)", idx, b.Value(start), b.Value(end), b.Value("…"), tokens))
+	query := fmt.Sprintf("SELECT %s FROM %s JOIN %s ON %s.rowid = %s.rowid WHERE %s MATCH %s ORDER BY %s.rank",
+		strings.Join(selects, ", "), idx, Quote(schema.Table), Quote(schema.Table), idx, idx, b.Value(req.Query), idx)
+
+	fetch := limit
+	if limit > 0 {
+		fetch++
+	}
+	query += limitClause(fetch, req.Offset)
+
+	return &SearchStatement{
+		Statement: Statement{SQL: query, Args: b.Args, Table: schema.Table, Limit: limit},
+		Columns:   columns,
+	}, nil
+}
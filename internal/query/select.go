@@ -0,0 +1,201 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+// Statement is a compiled SQL statement with its bound arguments.
+type Statement struct {
+	SQL  string
+	Args []interface{}
+	// Table is the primary table the statement reads from.
+	Table string
+	// Limit is the number of rows to return when the statement fetches one
+	// extra row to detect whether more rows match; zero otherwise.
+	Limit int64
+}
+
+// BuildSelect compiles a QueryData request into a SELECT statement. The
+// structured filter and the legacy raw condition are mutually exclusive.
+func BuildSelect(d db.Execer, req *proto.QueryDataRequest) (*Statement, error) {
+	return buildSelect(d, req, &Builder{})
+}
+
+// PrepareSelect is BuildSelect for prepared statements: filter nodes may
+// name parameters instead of giving values.
+func PrepareSelect(d db.Execer, req *proto.QueryDataRequest) (*Statement, error) {
+	return buildSelect(d, req, &Builder{Prepared: true})
+}
+
+func buildSelect(d db.Execer, req *proto.QueryDataRequest, b *Builder) (*Statement, error) {
+	schema, err := LoadSchema(d, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	scope := TableScope{Schema: schema}
+
+	columns, err := projection(req, scope)
+	if err != nil {
+		return nil, err
+	}
+	if req.Offset < 0 {
+		return nil, invalid("offset must not be negative")
+	}
+	limit, err := RowLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "SELECT "
+	if req.Distinct {
+		query += "DISTINCT "
+	}
+	query += fmt.Sprintf("%s FROM %s", columns, Quote(schema.Table))
+
+	switch {
+	case req.Filter != nil && req.Condition != "":
+		return nil, invalid("condition and filter cannot both be set")
+	case req.Filter != nil:
+		where, err := CompileFilter(req.Filter, scope, b)
+		if err != nil {
+			return nil, err
+		}
+		query += " WHERE " + where
+	case req.Condition != "":
+		// On lines of its own, a trailing "--" comment in the condition
+		// cannot swallow the ORDER BY and LIMIT that follow.
+		query += " WHERE (\n" + req.Condition + "\n)"
+	}
+
+	order, err := CompileOrderBy(req.OrderBy, scope)
+	if err != nil {
+		return nil, err
+	}
+	query += order
+
+	// Fetch one row beyond the limit so the caller can report has_more.
+	fetch := limit
+	if limit > 0 {
+		fetch++
+	}
+	query += limitClause(fetch, req.Offset)
+	if req.Condition != "" {
+		if err := singleStatement(query); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Statement{SQL: query, Args: b.Args, Table: schema.Table, Limit: limit}, nil
+}
+
+// PrepareDelete compiles a DeleteRecordRequest into a DELETE statement for
+// a prepared statement; filter nodes may name parameters. A filter or
+// condition is required so that every row is never deleted by accident; an
+// explicit condition such as "1 = 1" is needed for that.
+func PrepareDelete(d db.Execer, req *proto.DeleteRecordRequest) (*Statement, error) {
+	return buildDelete(d, req, &Builder{Prepared: true})
+}
+
+func buildDelete(d db.Execer, req *proto.DeleteRecordRequest, b *Builder) (*Statement, error) {
+	schema, err := LoadSchema(d, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	var where string
+	switch {
+	case req.Filter != nil && req.Condition != "":
+		return nil, invalid("condition and filter cannot both be set")
+	case req.Filter != nil:
+		where, err = CompileFilter(req.Filter, TableScope{Schema: schema}, b)
+		if err != nil {
+			return nil, err
+		}
+	case req.Condition != "":
+		where = req.Condition
+	default:
+		return nil, invalid("a condition or filter is required to delete records")
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", Quote(schema.Table), where)
+	if req.Condition != "" {
+		if err := singleStatement(query); err != nil {
+			return nil, err
+		}
+	}
+	return &Statement{SQL: query, Args: b.Args, Table: schema.Table}, nil
+}
+
+// Parameters lists the distinct parameters of a prepared statement in order
+// of first appearance.
+func (s *Statement) Parameters() []Parameter {
+	var params []Parameter
+	seen := map[string]bool{}
+	for _, arg := range s.Args {
+		if p, ok := arg.(Parameter); ok && !seen[p.Name] {
+			seen[p.Name] = true
+			params = append(params, p)
+		}
+	}
+	return params
+}
+
+// Bind returns the statement arguments with every parameter replaced by its
+// value. All parameters must be given and no others.
+func (s *Statement) Bind(values map[string]string) ([]interface{}, error) {
+	args := make([]interface{}, len(s.Args))
+	used := map[string]bool{}
+	for i, arg := range s.Args {
+		p, ok := arg.(Parameter)
+		if !ok {
+			args[i] = arg
+			continue
+		}
+		v, ok := values[p.Name]
+		if !ok {
+			return nil, invalid("missing value for parameter %q", p.Name)
+		}
+		used[p.Name] = true
+		b := &Builder{}
+		b.ValueFor(Reference{Numeric: p.Numeric, JSON: p.JSON}, v)
+		args[i] = b.Args[0]
+	}
+	for name := range values {
+		if !used[name] {
+			return nil, invalid("unknown parameter %q", name)
+		}
+	}
+	return args, nil
+}
+
+// projection renders the select list from the projection field or the
+// deprecated comma-separated columns string; both are validated against the
+// table schema.
+func projection(req *proto.QueryDataRequest, scope TableScope) (string, error) {
+	names := req.Projection
+	if legacy := strings.TrimSpace(req.Columns); legacy != "" && legacy != "*" {
+		if len(names) > 0 {
+			return "", invalid("columns and projection cannot both be set")
+		}
+		for _, name := range strings.Split(legacy, ",") {
+			names = append(names, strings.TrimSpace(name))
+		}
+	}
+	if len(names) == 0 {
+		return "*", nil
+	}
+
+	columns := make([]string, len(names))
+	for i, name := range names {
+		ref, err := scope.Resolve(name)
+		if err != nil {
+			return "", err
+		}
+		columns[i] = ref.SQL
+	}
+	return strings.Join(columns, ", "), nil
+}
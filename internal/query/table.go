@@ -0,0 +1,106 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+// CreateTableSQL renders the CREATE TABLE IF NOT EXISTS statement of a
+// CreateTable request. Columns are declared in name order.
+func CreateTableSQL(table string, columns map[string]string) (string, error) {
+	if err := ValidateIdentifier(table); err != nil {
+		return "", err
+	}
+	if db.IsInternalTable(table) {
+		return "", invalid("table %q is reserved", table)
+	}
+	if len(columns) == 0 {
+		return "", invalid("at least one column is required")
+	}
+	defs := make([]string, 0, len(columns))
+	for _, name := range sortedKeys(columns) {
+		def, err := ColumnDefinition(name, columns[name])
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, def)
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", Quote(table), strings.Join(defs, ", ")), nil
+}
+
+// AddColumnSQL renders the ALTER TABLE statement of an UpdateTable request.
+func AddColumnSQL(schema *Schema, column, declared string) (string, error) {
+	def, err := ColumnDefinition(column, declared)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", Quote(schema.Table), def), nil
+}
+
+// BuildInsert compiles the insertion of one record, given as column name to
+// value, into an INSERT statement. Columns are listed in name order.
+func BuildInsert(schema *Schema, record map[string]string) (*Statement, error) {
+	if len(record) == 0 {
+		return nil, invalid("a record needs at least one column")
+	}
+	scope := TableScope{Schema: schema}
+	columns := make([]string, 0, len(record))
+	placeholders := make([]string, 0, len(record))
+	args := make([]interface{}, 0, len(record))
+	for _, name := range sortedKeys(record) {
+		ref, err := scope.Resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, ref.SQL)
+		placeholders = append(placeholders, "?")
+		args = append(args, record[name])
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", Quote(schema.Table), strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+	return &Statement{SQL: query, Args: args, Table: schema.Table}, nil
+}
+
+// BuildUpdate compiles an UpdateRecord request. The assigned values are
+// bound; the legacy raw condition must not end the statement.
+func BuildUpdate(d db.Execer, req *proto.UpdateRecordRequest) (*Statement, error) {
+	schema, err := LoadSchema(d, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Updates) == 0 {
+		return nil, invalid("at least one column to update is required")
+	}
+	if req.Condition == "" {
+		return nil, invalid("a condition is required to update records")
+	}
+
+	scope := TableScope{Schema: schema}
+	sets := make([]string, 0, len(req.Updates))
+	args := make([]interface{}, 0, len(req.Updates))
+	for _, name := range sortedKeys(req.Updates) {
+		ref, err := scope.Resolve(name)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, ref.SQL+" = ?")
+		args = append(args, req.Updates[name])
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE (\n%s\n)", Quote(schema.Table), strings.Join(sets, ", "), req.Condition)
+	if err := singleStatement(query); err != nil {
+		return nil, err
+	}
+	return &Statement{SQL: query, Args: args, Table: schema.Table}, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+package query
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+)
+
+func openTestTable(t *testing.T) *sql.DB {
+	t.Helper()
+	database, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { database.Close() })
+	database.SetMaxOpenConns(1)
+	if _, err := database.Exec(`CREATE TABLE t (id INTEGER PRIMARY KEY, "order" TEXT, name TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	return database
+}
+
+func TestCreateTableSQL(t *testing.T) {
+	got, err := CreateTableSQL("items", map[string]string{"name": "TEXT", "id": "INTEGER PRIMARY KEY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `CREATE TABLE IF NOT EXISTS "items" ("id" INTEGER PRIMARY KEY, "name" TEXT)`
+	if got != want {
+		t.Errorf("CreateTableSQL = %s, want %s", got, want)
+	}
+
+	for _, table := range []string{"x AS SELECT 1; ATTACH DATABASE 'a' AS b; --", "indexes", "schema_migrations", "sqlite_stat1", ""} {
+		if _, err := CreateTableSQL(table, map[string]string{"a": "TEXT"}); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("CreateTableSQL(%q) = %v, want an invalid query error", table, err)
+		}
+	}
+	if _, err := CreateTableSQL("items", nil); !errors.Is(err, ErrInvalidQuery) {
+		t.Errorf("CreateTableSQL without columns = %v, want an invalid query error", err)
+	}
+}
+
+func TestBuildInsert(t *testing.T) {
+	schema, err := LoadSchema(openTestTable(t), "t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt, err := BuildInsert(schema, map[string]string{"order": "o1", "name": "n"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `INSERT INTO "t" ("name", "order") VALUES (?, ?)`; stmt.SQL != want {
+		t.Errorf("BuildInsert SQL = %s, want %s", stmt.SQL, want)
+	}
+	if len(stmt.Args) != 2 || stmt.Args[0] != "n" || stmt.Args[1] != "o1" {
+		t.Errorf("BuildInsert args = %v", stmt.Args)
+	}
+
+	for _, record := range []map[string]string{
+		{"name) VALUES (1); ATTACH DATABASE 'a' AS b; --": "x"},
+		{"missing": "x"},
+		{},
+	} {
+		if _, err := BuildInsert(schema, record); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("BuildInsert(%v) = %v, want an invalid query error", record, err)
+		}
+	}
+}
+
+func TestBuildUpdate(t *testing.T) {
+	database := openTestTable(t)
+	stmt, err := BuildUpdate(database, &proto.UpdateRecordRequest{TableName: "t", Updates: map[string]string{"name": "n"}, Condition: "id = 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "UPDATE \"t\" SET \"name\" = ? WHERE (\nid = 1\n)"; stmt.SQL != want {
+		t.Errorf("BuildUpdate SQL = %q, want %q", stmt.SQL, want)
+	}
+
+	for _, req := range []*proto.UpdateRecordRequest{
+		{TableName: "t SET name = 1; ATTACH DATABASE 'a' AS b; --", Updates: map[string]string{"name": "n"}, Condition: "1"},
+		{TableName: "t", Updates: map[string]string{"name = 1; --": "n"}, Condition: "1"},
+		{TableName: "t", Updates: map[string]string{"name": "n"}, Condition: "1); ATTACH DATABASE 'a' AS b; --"},
+		{TableName: "t", Updates: map[string]string{"name": "n"}},
+		{TableName: "t", Condition: "1"},
+		{TableName: "indexes", Updates: map[string]string{"columns": "n"}, Condition: "1"},
+	} {
+		if _, err := BuildUpdate(database, req); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("BuildUpdate(%v) = %v, want an invalid query error", req, err)
+		}
+	}
+}
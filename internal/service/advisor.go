@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/prakhar-5447/GoDB/internal/advisor"
+	"github.com/prakhar-5447/GoDB/internal/audit"
+	"github.com/prakhar-5447/GoDB/internal/auth"
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/query"
+	"github.com/prakhar-5447/GoDB/internal/stats"
+	"github.com/prakhar-5447/GoDB/internal/tracing"
+)
+
+// RecommendIndexes suggests indexes for the caller's database based on the
+// statements the server has executed against it.
+func (s *DatabaseServiceServer) RecommendIndexes(ctx context.Context, req *proto.RecommendIndexesRequest) (*proto.RecommendIndexesResponse, error) {
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	policy, err := advisor.LoadPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	recommendations, err := advisor.Recommend(database, databaseKey(req.ConnectionString), policy)
+	if err != nil {
+		return nil, err
+	}
+
+	var response proto.RecommendIndexesResponse
+	for _, rec := range recommendations {
+		entry := &proto.IndexRecommendation{
+			Index:            recommendationRequest(rec),
+			EstimatedBenefit: rec.Benefit,
+			Occurrences:      rec.Occurrences,
+			Reason:           rec.Reason,
+		}
+		if req.Apply {
+			if _, err := createIndex(database, entry.Index); err != nil {
+				return nil, fmt.Errorf("failed to create index %s: %w", entry.Index.IndexName, err)
+			}
+			entry.Applied = true
+			audit.LogEvent(fmt.Sprintf("Created recommended index %s on %s", entry.Index.IndexName, rec.Table))
+		}
+		response.Recommendations = append(response.Recommendations, entry)
+	}
+
+	return &response, nil
+}
+
+// SetIndexAdvisorPolicy stores the server-wide index advisor policy. Admin only.
+func (s *DatabaseServiceServer) SetIndexAdvisorPolicy(ctx context.Context, req *proto.SetIndexAdvisorPolicyRequest) (*proto.SetIndexAdvisorPolicyResponse, error) {
+	admin, err := requireAdmin(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	if req.Policy == nil {
+		return nil, fmt.Errorf("policy is required")
+	}
+
+	policy := advisor.Policy{
+		AutoApply:          req.Policy.AutoApply,
+		MinOccurrences:     req.Policy.MinOccurrences,
+		MinBenefit:         req.Policy.MinBenefit,
+		LargeTableRows:     req.Policy.LargeTableRows,
+		MaxIndexesPerTable: int(req.Policy.MaxIndexesPerTable),
+		Interval:           time.Duration(req.Policy.IntervalSeconds) * time.Second,
+	}
+	if err := advisor.SavePolicy(policy); err != nil {
+		return nil, err
+	}
+	audit.LogEvent(fmt.Sprintf("Admin %s updated the index advisor policy (auto_apply=%t)", admin, policy.AutoApply))
+
+	return &proto.SetIndexAdvisorPolicyResponse{Message: "Index advisor policy updated", Policy: req.Policy}, nil
+}
+
+// SetUserRole changes another user's role. Admin only.
+func (s *DatabaseServiceServer) SetUserRole(ctx context.Context, req *proto.SetUserRoleRequest) (*proto.SetUserRoleResponse, error) {
+	admin, err := requireAdmin(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	if err := auth.SetUserRole(req.Username, req.Role); err != nil {
+		return nil, err
+	}
+	audit.LogEvent(fmt.Sprintf("Admin %s set role of %s to %s", admin, req.Username, req.Role))
+
+	return &proto.SetUserRoleResponse{Message: "Role updated successfully"}, nil
+}
+
+// requireAdmin authenticates the connection string and checks for the admin
+// role. The database part of the connection string is not used.
+func requireAdmin(ctx context.Context, connectionString string) (string, error) {
+	info, err := db.ParseConnection(connectionString)
+	if err != nil {
+		return "", err
+	}
+	_, span := tracing.Start(ctx, "auth.RequireAdmin", tracing.KindInternal)
+	span.SetAttribute("enduser.id", info.Username)
+	err = auth.RequireAdmin(info.Username, info.Password)
+	span.SetError(err)
+	span.End()
+	// A user who is not an admin still presented valid credentials.
+	if err == nil || errors.Is(err, auth.ErrPermissionDenied) {
+		auth.MarkVerified(ctx, info.Username)
+	}
+	if err != nil {
+		return "", err
+	}
+	return info.Username, nil
+}
+
+// StartIndexAdvisor runs the index advisor in the background until ctx is
+// cancelled. Each run re-reads the policy so admin changes apply without a
+// restart; indexes are only created when the policy enables auto-apply. The
+// returned channel is closed once the advisor has stopped and closed the
+// databases it opened.
+func StartIndexAdvisor(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			policy, err := advisor.LoadPolicy()
+			if err != nil {
+				log.Printf("Index advisor: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(policy.Interval):
+			}
+			runIndexAdvisor(ctx, policy)
+		}
+	}()
+	return done
+}
+
+// runIndexAdvisor checks every database with a recorded workload, stopping
+// early when ctx is cancelled.
+func runIndexAdvisor(ctx context.Context, policy advisor.Policy) {
+	for _, key := range stats.WorkloadKeys() {
+		if ctx.Err() != nil {
+			return
+		}
+		username, dbName, ok := strings.Cut(key, "/")
+		if !ok {
+			continue
+		}
+		database, err := db.OpenUserDatabase(username, dbName)
+		if err != nil {
+			log.Printf("Index advisor: skipping %s: %v", key, err)
+			continue
+		}
+
+		recommendations, err := advisor.Recommend(database, key, policy)
+		if err != nil {
+			log.Printf("Index advisor: %s: %v", key, err)
+		}
+		for _, rec := range recommendations {
+			if !policy.AutoApply {
+				log.Printf("Index advisor: %s: recommend %s (%s)", key, rec.IndexName(), rec.Reason)
+				continue
+			}
+			if _, err := createIndex(database, recommendationRequest(rec)); err != nil {
+				log.Printf("Index advisor: %s: failed to create %s: %v", key, rec.IndexName(), err)
+				continue
+			}
+			audit.LogEvent(fmt.Sprintf("Index advisor created index %s on %s for %s", rec.IndexName(), rec.Table, key))
+		}
+		database.Close()
+	}
+}
+
+// recommendationRequest turns a recommendation into an AddIndex request.
+func recommendationRequest(rec advisor.Recommendation) *proto.AddIndexRequest {
+	return &proto.AddIndexRequest{
+		TableName:   rec.Table,
+		IndexName:   rec.IndexName(),
+		Columns:     rec.Columns,
+		IfNotExists: true,
+	}
+}
+
+// recordFilterAccess adds the filter and sort columns of a statement to the
+// workload used for index recommendations.
+func recordFilterAccess(database db.Execer, connectionString, table string, filter *proto.Filter, condition string, orderBy ...*proto.OrderBy) {
+	pattern := stats.AccessPattern{Table: table}
+	for _, o := range orderBy {
+		pattern.Sort = append(pattern.Sort, o.Column)
+	}
+	switch {
+	case filter != nil:
+		pattern.Equality, pattern.Range = query.FilterAccess(filter)
+	case condition != "":
+		schema, err := query.LoadSchema(database, table)
+		if err != nil {
+			return
+		}
+		pattern.Equality, pattern.Range = query.ConditionAccess(condition, schema)
+	}
+	stats.RecordAccess(databaseKey(connectionString), pattern)
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prakhar-5447/GoDB/internal/audit"
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/query"
+	"github.com/prakhar-5447/GoDB/internal/stats"
+)
+
+// AggregateQuery runs a grouped query with aggregate functions.
+func (s *DatabaseServiceServer) AggregateQuery(ctx context.Context, req *proto.AggregateQueryRequest) (*proto.AggregateQueryResponse, error) {
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	stmt, err := query.BuildAggregate(database, req)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.LogEvent(fmt.Sprintf("Executing aggregate query: %s", stmt.SQL))
+
+	rows, err := database.Query(stmt.SQL, stmt.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result, _, err := scanQueryRows(rows, 0)
+	if err != nil {
+		return nil, err
+	}
+	rows.Close()
+	recordPlan(database, req.ConnectionString, &stmt.Statement)
+
+	// Grouping benefits from an index on the group-by columns much like sorting does.
+	pattern := stats.AccessPattern{Table: stmt.Table, Sort: req.GroupBy}
+	pattern.Equality, pattern.Range = query.FilterAccess(req.Filter)
+	stats.RecordAccess(databaseKey(req.ConnectionString), pattern)
+
+	return &proto.AggregateQueryResponse{Rows: result, Columns: stmt.Columns}, nil
+}
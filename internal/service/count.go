@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prakhar-5447/GoDB/internal/audit"
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/query"
+)
+
+// CountRecords counts the rows matching a filter. With approximate set, an
+// estimate from sqlite_stat1 is returned when one is available.
+func (s *DatabaseServiceServer) CountRecords(ctx context.Context, req *proto.CountRecordsRequest) (*proto.CountRecordsResponse, error) {
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	stmt, err := query.BuildCount(database, req.TableName, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Approximate {
+		count, ok, err := query.EstimateCount(database, stmt.Table, req.Filter)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &proto.CountRecordsResponse{Count: count, Approximate: true}, nil
+		}
+	}
+
+	audit.LogEvent(fmt.Sprintf("Executing count: %s", stmt.SQL))
+
+	var count int64
+	if err := database.QueryRow(stmt.SQL, stmt.Args...).Scan(&count); err != nil {
+		return nil, err
+	}
+	recordPlan(database, req.ConnectionString, stmt)
+	recordFilterAccess(database, req.ConnectionString, stmt.Table, req.Filter, "")
+
+	return &proto.CountRecordsResponse{Count: count}, nil
+}
+
+// Exists reports whether any row matches a filter.
+func (s *DatabaseServiceServer) Exists(ctx context.Context, req *proto.ExistsRequest) (*proto.ExistsResponse, error) {
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	stmt, err := query.BuildExists(database, req.TableName, req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.LogEvent(fmt.Sprintf("Executing existence check: %s", stmt.SQL))
+
+	var exists bool
+	if err := database.QueryRow(stmt.SQL, stmt.Args...).Scan(&exists); err != nil {
+		return nil, err
+	}
+	recordPlan(database, req.ConnectionString, stmt)
+	recordFilterAccess(database, req.ConnectionString, stmt.Table, req.Filter, "")
+
+	return &proto.ExistsResponse{Exists: exists}, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/query"
+	"github.com/prakhar-5447/GoDB/internal/stats"
+)
+
+// defaultLargeTableRows is the row count above which full scans are flagged.
+const defaultLargeTableRows = 10000
+
+// ExplainQuery returns the SQLite query plan for a QueryData request without running it.
+func (s *DatabaseServiceServer) ExplainQuery(ctx context.Context, req *proto.QueryDataRequest) (*proto.ExplainQueryResponse, error) {
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	stmt, err := query.BuildSelect(database, req)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := query.Explain(database, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.ExplainQueryResponse{
+		Sql:         stmt.SQL,
+		Plan:        planNodesToProto(plan.Roots),
+		IndexesUsed: plan.IndexesUsed(),
+		FullScans:   plan.FullScans(),
+	}, nil
+}
+
+// GetIndexUsage reports which indexes the queries executed by this server
+// have used, which indexes were never hit, and which tables were scanned.
+func (s *DatabaseServiceServer) GetIndexUsage(ctx context.Context, req *proto.IndexUsageRequest) (*proto.IndexUsageResponse, error) {
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	largeRows := req.LargeTableRows
+	if largeRows <= 0 {
+		largeRows = defaultLargeTableRows
+	}
+
+	key := databaseKey(req.ConnectionString)
+	usage := stats.IndexUsage(key)
+	response := &proto.IndexUsageResponse{QueriesRecorded: usage.Queries}
+
+	hits := map[string]stats.IndexHit{}
+	for _, hit := range usage.Indexes {
+		hits[hit.Index] = hit
+	}
+
+	indexes, err := db.ListIndexes(database, "")
+	if err != nil {
+		return nil, err
+	}
+	for _, idx := range indexes {
+		entry := &proto.IndexUsage{IndexName: idx.Name, TableName: idx.Table, Unused: true}
+		if hit, ok := hits[idx.Name]; ok {
+			entry.Hits = hit.Hits
+			entry.LastUsed = hit.LastUsed.Format(time.RFC3339)
+			entry.Unused = false
+		}
+		response.Indexes = append(response.Indexes, entry)
+	}
+
+	for _, scan := range usage.Scans {
+		entry := &proto.TableScanUsage{TableName: scan.Table, FullScans: scan.FullScans}
+		if exists, err := db.TableExists(database, scan.Table); err == nil && exists {
+			entry.RowCount, err = db.RowCount(database, key, scan.Table)
+			if err != nil {
+				return nil, err
+			}
+			entry.Large = entry.RowCount >= largeRows
+		}
+		response.Scans = append(response.Scans, entry)
+	}
+
+	return response, nil
+}
+
+// recordPlan explains an executed statement and adds its table accesses to
+// the index usage counters. Failures are logged but never fail the RPC.
+func recordPlan(database db.Execer, connectionString string, stmt *query.Statement) {
+	plan, err := query.Explain(database, stmt)
+	if err != nil {
+		log.Printf("Failed to explain statement for index usage: %v", err)
+		return
+	}
+	stats.RecordPlan(databaseKey(connectionString), plan)
+}
+
+// databaseKey identifies a user database as "username/database".
+func databaseKey(connectionString string) string {
+	info, err := db.ParseConnection(connectionString)
+	if err != nil {
+		return ""
+	}
+	return info.Username + "/" + info.Database
+}
+
+func planNodesToProto(nodes []*query.PlanNode) []*proto.PlanNode {
+	var out []*proto.PlanNode
+	for _, n := range nodes {
+		out = append(out, &proto.PlanNode{
+			Id:       int32(n.ID),
+			Parent:   int32(n.Parent),
+			Detail:   n.Detail,
+			Children: planNodesToProto(n.Children),
+		})
+	}
+	return out
+}
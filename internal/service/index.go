@@ -1,111 +1,260 @@
-package service 
+package service
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 
 	"github.com/prakhar-5447/GoDB/internal/db"
 	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/query"
 )
 // Add Index
 func (s *DatabaseServiceServer) AddIndex(ctx context.Context, req *proto.AddIndexRequest) (*proto.AddIndexResponse, error) {
-	database, err := db.OpenDatabase(req.ConnectionString)
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
 	if err != nil {
 		return nil, err
 	}
 	defer database.Close()
 
-	// Ensure each database has an `indexes` table
-	_, err = database.Exec(`CREATE TABLE IF NOT EXISTS indexes (
-		id INTEGER PRIMARY KEY AUTOINCREMENT,
-		table_name TEXT NOT NULL,
-		index_name TEXT NOT NULL UNIQUE,
-		columns TEXT NOT NULL,
-		UNIQUE(user_id, index_name)
-	)`)
+	return createIndex(database, req)
+}
+
+// createIndex builds and runs the CREATE INDEX statement for a request. It is
+// shared by AddIndex and the index advisor.
+func createIndex(database *sql.DB, req *proto.AddIndexRequest) (*proto.AddIndexResponse, error) {
+	schema, err := query.LoadSchema(database, req.TableName)
 	if err != nil {
 		return nil, err
 	}
+	scope := query.TableScope{Schema: schema}
+
+	// Plain column names are shorthand for ascending keys.
+	keys := req.Keys
+	if len(keys) == 0 {
+		for _, col := range req.Columns {
+			keys = append(keys, &proto.IndexColumn{Column: col})
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one index column is required")
+	}
+
+	var keySQL, keyNames []string
+	for _, key := range keys {
+		expr, err := query.CompileIndexKey(key, scope)
+		if err != nil {
+			return nil, err
+		}
+		keySQL = append(keySQL, expr)
+		keyNames = append(keyNames, query.IndexKeyName(key))
+	}
 
 	// Generate index name if not provided
 	indexName := req.IndexName
 	if indexName == "" {
-		indexName = fmt.Sprintf("%s_%s_idx", req.TableName, strings.Join(req.Columns, "_"))
+		indexName = fmt.Sprintf("%s_%s_idx", req.TableName, strings.Join(keyNames, "_"))
+	}
+	if err := query.ValidateIdentifier(indexName); err != nil {
+		return nil, err
 	}
 
-	// Create index in the table
-	query := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", indexName, req.TableName, strings.Join(req.Columns, ", "))
-	_, err = database.Exec(query)
+	// Partial index predicates cannot use bound parameters, so values are
+	// rendered as escaped literals.
+	where, err := query.CompileFilter(req.Where, scope, &query.Builder{Inline: true})
 	if err != nil {
 		return nil, err
 	}
 
-	// Store index metadata
-	_, err = database.Exec("INSERT INTO indexes (table_name, index_name, columns) VALUES (?, ?, ?)",
-		req.TableName, indexName, strings.Join(req.Columns, ", "))
+	var count int
+	err = database.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?", indexName).Scan(&count)
 	if err != nil {
 		return nil, err
 	}
+	if count > 0 {
+		if !req.IfNotExists {
+			return nil, fmt.Errorf("index '%s' already exists", indexName)
+		}
+		return &proto.AddIndexResponse{Message: "Index already exists", IndexName: indexName, AlreadyExisted: true}, nil
+	}
 
-	return &proto.AddIndexResponse{Message: "Index created successfully!"}, nil
+	// Create the index and record it in the legacy metadata table together,
+	// so a failed insert cannot leave an untracked index behind.
+	tx, err := database.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	createSQL := "CREATE INDEX"
+	if req.Unique {
+		createSQL = "CREATE UNIQUE INDEX"
+	}
+	stmt := fmt.Sprintf("%s %s ON %s (%s)", createSQL, db.QuoteIdentifier(indexName), db.QuoteIdentifier(schema.Table), strings.Join(keySQL, ", "))
+	if where != "" {
+		stmt += " WHERE " + where
+	}
+	_, err = tx.Exec(stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store index metadata
+	if err := db.RecordIndexMetadata(tx, schema.Table, indexName, strings.Join(keySQL, ", ")); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &proto.AddIndexResponse{Message: "Index created successfully!", IndexName: indexName}, nil
 }
 
 // Delete Index
 func (s *DatabaseServiceServer) DeleteIndex(ctx context.Context, req *proto.DeleteIndexRequest) (*proto.DeleteIndexResponse, error) {
-	database, err := db.OpenDatabase(req.ConnectionString)
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
 	if err != nil {
 		return nil, err
 	}
 	defer database.Close()
 
-	// Check if the index exists for the user
+	// Check that the index exists in SQLite's catalog
 	var count int
-	err = database.QueryRow("SELECT COUNT(*) FROM indexes WHERE index_name = ?", req.IndexName).Scan(&count)
+	err = database.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?", req.IndexName).Scan(&count)
 	if err != nil {
 		return nil, err
 	}
 	if count == 0 {
-		return nil, fmt.Errorf("index '%s' not found", req.IndexName)
+		search, ok, err := db.SearchIndex(database, req.IndexName)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, fmt.Errorf("index '%s' not found", req.IndexName)
+		}
+		if err := dropSearchIndex(database, search); err != nil {
+			return nil, err
+		}
+		return &proto.DeleteIndexResponse{Message: "Index deleted successfully!"}, nil
 	}
 
 	// Drop the index from the database
-	_, err = database.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", req.IndexName))
+	_, err = database.Exec(fmt.Sprintf("DROP INDEX IF EXISTS %s", db.QuoteIdentifier(req.IndexName)))
 	if err != nil {
 		return nil, err
 	}
 
 	// Remove index metadata
-	_, err = database.Exec("DELETE FROM indexes WHEREindex_name = ?", req.IndexName)
+	exists, err := db.TableExists(database, db.LegacyIndexTable)
 	if err != nil {
 		return nil, err
 	}
+	if exists {
+		_, err = database.Exec("DELETE FROM indexes WHERE index_name = ?", req.IndexName)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &proto.DeleteIndexResponse{Message: "Index deleted successfully!"}, nil
 }
 
-// List Indexes
+// List Indexes reads SQLite's catalog, so indexes created by constraints or
+// outside of AddIndex are reported too.
 func (s *DatabaseServiceServer) ListIndexes(ctx context.Context, req *proto.ListIndexesRequest) (*proto.ListIndexesResponse, error) {
-	database, err := db.OpenDatabase(req.ConnectionString)
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
 	if err != nil {
 		return nil, err
 	}
 	defer database.Close()
 
-	rows, err := database.Query("SELECT index_name, table_name, columns FROM indexes")
+	if req.TableName != "" {
+		exists, err := db.TableExists(database, req.TableName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("table '%s' not found", req.TableName)
+		}
+	}
+
+	infos, err := db.ListIndexes(database, req.TableName)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
 	var indexes []*proto.Index
-	for rows.Next() {
-		var index proto.Index
-		err := rows.Scan(&index.IndexName, &index.TableName, &index.Columns)
+	for _, info := range infos {
+		indexes = append(indexes, &proto.Index{
+			IndexName: info.Name,
+			TableName: info.Table,
+			Columns:   strings.Join(info.Columns, ", "),
+			Unique:    info.Unique,
+			Partial:   info.Partial,
+			Where:     info.Where,
+			Origin:    info.Origin,
+		})
+	}
+
+	searchIndexes, err := db.SearchIndexes(database, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	for _, info := range searchIndexes {
+		indexes = append(indexes, &proto.Index{
+			IndexName: info.Name,
+			TableName: info.Table,
+			Columns:   strings.Join(info.Columns, ", "),
+			Origin:    db.IndexOriginSearch,
+		})
+	}
+
+	return &proto.ListIndexesResponse{Indexes: indexes}, nil
+}
+
+// RepairIndexMetadata brings the legacy `indexes` table in line with SQLite's
+// catalog, or drops it when requested.
+func (s *DatabaseServiceServer) RepairIndexMetadata(ctx context.Context, req *proto.RepairIndexMetadataRequest) (*proto.RepairIndexMetadataResponse, error) {
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	if req.DropLegacyTable {
+		dropped, err := db.DropIndexMetadata(database)
 		if err != nil {
 			return nil, err
 		}
-		indexes = append(indexes, &index)
+		return &proto.RepairIndexMetadataResponse{Message: "Index metadata table removed", Dropped: dropped}, nil
 	}
 
-	return &proto.ListIndexesResponse{Indexes: indexes}, nil
-}
\ No newline at end of file
+	added, removed, err := db.ReconcileIndexMetadata(database)
+	if err != nil {
+		return nil, err
+	}
+
+	return &proto.RepairIndexMetadataResponse{
+		Message: fmt.Sprintf("Index metadata reconciled: %d added, %d removed", added, removed),
+		Added:   int32(added),
+		Removed: int32(removed),
+	}, nil
+}
+
+// dropSearchIndex removes a search index together with its sync triggers.
+func dropSearchIndex(database *sql.DB, index db.SearchIndexInfo) error {
+	tx, err := database.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, trigger := range db.SearchTriggerNames(index.Name) {
+		if _, err := tx.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s", db.QuoteIdentifier(trigger))); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(fmt.Sprintf("DROP TABLE %s", db.QuoteIdentifier(index.Name))); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
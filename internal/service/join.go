@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prakhar-5447/GoDB/internal/audit"
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/query"
+)
+
+// sqliteTimeFormat is how SQLite's date functions write timestamps.
+const sqliteTimeFormat = "2006-01-02 15:04:05.999999999"
+
+// JoinQuery runs a query over several joined tables and returns typed rows
+// whose columns are qualified with their table alias.
+func (s *DatabaseServiceServer) JoinQuery(ctx context.Context, req *proto.JoinQueryRequest) (*proto.JoinQueryResponse, error) {
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	stmt, err := query.BuildJoin(database, req)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.LogEvent(fmt.Sprintf("Executing join query: %s", stmt.SQL))
+
+	rows, err := database.Query(stmt.SQL, stmt.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var response proto.JoinQueryResponse
+	for _, c := range stmt.Columns {
+		response.Columns = append(response.Columns, &proto.ResultColumn{
+			Name:         c.Name(),
+			Table:        c.Table,
+			Column:       c.Column.Name,
+			DeclaredType: c.Column.Type,
+		})
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(stmt.Columns))
+		valuePtrs := make([]interface{}, len(values))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+
+		row := &proto.TypedRow{Values: make([]*proto.Value, len(values))}
+		for i, v := range values {
+			row.Values[i] = typedValue(v)
+		}
+		response.Rows = append(response.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	recordPlan(database, req.ConnectionString, &stmt.Statement)
+
+	return &response, nil
+}
+
+// typedValue converts a value scanned from SQLite into its storage class.
+func typedValue(v interface{}) *proto.Value {
+	switch v := v.(type) {
+	case nil:
+		return &proto.Value{Kind: proto.Value_NULL}
+	case int64:
+		return &proto.Value{Kind: proto.Value_INTEGER, IntegerValue: v}
+	case float64:
+		return &proto.Value{Kind: proto.Value_REAL, RealValue: v}
+	case bool:
+		var n int64
+		if v {
+			n = 1
+		}
+		return &proto.Value{Kind: proto.Value_INTEGER, IntegerValue: n}
+	case []byte:
+		return &proto.Value{Kind: proto.Value_BLOB, BlobValue: v}
+	case time.Time:
+		// The driver parses DATE/DATETIME/TIMESTAMP columns; report them as the text SQLite stores.
+		layout := sqliteTimeFormat
+		if v.Location() != time.UTC {
+			layout += "-07:00"
+		}
+		return &proto.Value{Kind: proto.Value_TEXT, TextValue: v.Format(layout)}
+	case string:
+		return &proto.Value{Kind: proto.Value_TEXT, TextValue: v}
+	}
+	return &proto.Value{Kind: proto.Value_TEXT, TextValue: fmt.Sprintf("%v", v)}
+}
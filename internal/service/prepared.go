@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prakhar-5447/GoDB/internal/audit"
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/prepared"
+	"github.com/prakhar-5447/GoDB/internal/query"
+)
+
+// PrepareStatement compiles a structured query or delete once and keeps it
+// for repeated execution through ExecutePrepared.
+func (s *DatabaseServiceServer) PrepareStatement(ctx context.Context, req *proto.PrepareStatementRequest) (*proto.PrepareStatementResponse, error) {
+	info, err := db.Authenticate(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	var kind prepared.Kind
+	var build func(*sql.DB) (*query.Statement, error)
+	switch {
+	case req.Query != nil && req.Delete != nil:
+		return nil, fmt.Errorf("query and delete cannot both be set")
+	case req.Query != nil:
+		kind = prepared.KindQuery
+		build = func(database *sql.DB) (*query.Statement, error) { return query.PrepareSelect(database, req.Query) }
+	case req.Delete != nil:
+		if info.ReadOnly() {
+			return nil, fmt.Errorf("cannot prepare a delete on a read-only connection")
+		}
+		kind = prepared.KindExec
+		build = func(database *sql.DB) (*query.Statement, error) { return query.PrepareDelete(database, req.Delete) }
+	default:
+		return nil, fmt.Errorf("a query or delete to prepare is required")
+	}
+
+	// Legacy conditions are raw SQL, so statements must pass the authorizer
+	// like the ones other RPCs run on a restricted connection.
+	vetted := func(database *sql.DB) (*query.Statement, error) {
+		stmt, err := build(database)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Vet(ctx, database, stmt.SQL); err != nil {
+			return nil, err
+		}
+		return stmt, nil
+	}
+
+	stmt, err := prepared.Prepare(sessionKey(info), info.Username, kind, func() (*sql.DB, error) { return db.OpenAuthenticated(ctx, info) }, vetted)
+	if err != nil {
+		return nil, err
+	}
+	audit.LogEvent(fmt.Sprintf("Prepared statement %s: %s", stmt.ID, stmt.SQL))
+
+	response := &proto.PrepareStatementResponse{StatementId: stmt.ID, Sql: stmt.SQL}
+	for _, p := range stmt.Parameters() {
+		response.Parameters = append(response.Parameters, &proto.PreparedParameter{
+			Name:         p.Name,
+			Column:       p.Column,
+			DeclaredType: p.Type,
+		})
+	}
+	return response, nil
+}
+
+// ExecutePrepared runs a prepared statement with the given parameter values.
+func (s *DatabaseServiceServer) ExecutePrepared(ctx context.Context, req *proto.ExecutePreparedRequest) (*proto.ExecutePreparedResponse, error) {
+	info, err := db.Authenticate(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := prepared.Lookup(sessionKey(info), req.StatementId)
+	if err != nil {
+		return nil, err
+	}
+	args, err := stmt.Bind(req.Params)
+	if err != nil {
+		return nil, err
+	}
+
+	var response proto.ExecutePreparedResponse
+	if stmt.Kind == prepared.KindExec {
+		result, err := stmt.Stmt.ExecContext(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		if response.RowsAffected, err = result.RowsAffected(); err != nil {
+			return nil, err
+		}
+		return &response, nil
+	}
+
+	rows, err := stmt.Stmt.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result, _, err := scanQueryRows(rows, stmt.Limit)
+	if err != nil {
+		return nil, err
+	}
+	response.Rows, response.HasMore, response.NextCursor = pageRows(result, stmt.Limit)
+	return &response, nil
+}
+
+// ClosePrepared releases a prepared statement.
+func (s *DatabaseServiceServer) ClosePrepared(ctx context.Context, req *proto.ClosePreparedRequest) (*proto.ClosePreparedResponse, error) {
+	info, err := db.Authenticate(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	if err := prepared.Close(sessionKey(info), req.StatementId); err != nil {
+		return nil, err
+	}
+	return &proto.ClosePreparedResponse{Message: "Prepared statement closed"}, nil
+}
+
+// sessionKey identifies the database handle prepared statements belong to.
+// Connections with different options get separate handles.
+func sessionKey(info *db.ConnectionInfo) string {
+	o := info.Options
+	return fmt.Sprintf("%s/%s?mode=%s&timeout=%s&journal=%s", info.Username, info.Database, o.Mode, o.BusyTimeout, o.JournalMode)
+}
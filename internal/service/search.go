@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prakhar-5447/GoDB/internal/audit"
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/query"
+)
+
+// errSearchUnavailable is returned when the server was built without FTS5.
+var errSearchUnavailable = fmt.Errorf("full-text search is not available: the server was built without the sqlite_fts5 tag")
+
+// CreateSearchIndex builds an FTS5 index over columns of a table. Triggers
+// keep it in sync with later inserts, updates and deletes.
+func (s *DatabaseServiceServer) CreateSearchIndex(ctx context.Context, req *proto.CreateSearchIndexRequest) (*proto.CreateSearchIndexResponse, error) {
+	if !db.FTS5Enabled {
+		return nil, errSearchUnavailable
+	}
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	schema, err := query.LoadSchema(database, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	name := req.IndexName
+	if name == "" {
+		name = schema.Table + "_search"
+	}
+	statements, err := query.SearchIndexStatements(schema, name, req.Columns, req.Tokenizer)
+	if err != nil {
+		return nil, err
+	}
+
+	// The index and its triggers share the schema namespace with tables and indexes.
+	var count int
+	err = database.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name IN (?, ?, ?, ?) COLLATE NOCASE",
+		name, db.SearchTriggerNames(name)[0], db.SearchTriggerNames(name)[1], db.SearchTriggerNames(name)[2]).Scan(&count)
+	if err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, fmt.Errorf("index '%s' already exists", name)
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	audit.LogEvent(fmt.Sprintf("Created search index %s on %s", name, schema.Table))
+
+	return &proto.CreateSearchIndexResponse{Message: "Search index created successfully!", IndexName: name}, nil
+}
+
+// Search runs a full-text query against a search index and returns the
+// matching rows, best match first, with highlighted snippets.
+func (s *DatabaseServiceServer) Search(ctx context.Context, req *proto.SearchRequest) (*proto.SearchResponse, error) {
+	if !db.FTS5Enabled {
+		return nil, errSearchUnavailable
+	}
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	index, ok, err := db.SearchIndex(database, req.IndexName)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("search index '%s' not found", req.IndexName)
+	}
+	schema, err := query.LoadSchema(database, index.Table)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := query.BuildSearch(index, schema, req)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.LogEvent(fmt.Sprintf("Executing search: %s", stmt.SQL))
+
+	rows, err := database.Query(stmt.SQL, stmt.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var response proto.SearchResponse
+	for rows.Next() {
+		if stmt.Limit > 0 && int64(len(response.Hits)) == stmt.Limit {
+			response.HasMore = true
+			break
+		}
+		values := make([]interface{}, len(stmt.Columns))
+		valuePtrs := make([]interface{}, 0, len(values)+2)
+		for i := range values {
+			valuePtrs = append(valuePtrs, &values[i])
+		}
+		var rank float64
+		var snippet sql.NullString
+		if err := rows.Scan(append(valuePtrs, &rank, &snippet)...); err != nil {
+			return nil, err
+		}
+
+		row := &proto.QueryRow{Data: make(map[string]string)}
+		for i, col := range stmt.Columns {
+			row.Data[col] = fmt.Sprintf("%v", values[i])
+		}
+		response.Hits = append(response.Hits, &proto.SearchHit{Row: row, Rank: rank, Snippet: snippet.String})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/query"
+	"github.com/prakhar-5447/GoDB/internal/slowlog"
+)
+
+// TopSlowQueries returns the normalized statements with the slowest
+// executions seen by the slow query log, or those that spent the most time
+// in total. Admin only.
+func (s *DatabaseServiceServer) TopSlowQueries(ctx context.Context, req *proto.TopSlowQueriesRequest) (*proto.TopSlowQueriesResponse, error) {
+	if _, err := requireAdmin(ctx, req.ConnectionString); err != nil {
+		return nil, err
+	}
+	limit, err := query.RowLimit(req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &proto.TopSlowQueriesResponse{}
+	for _, stats := range slowlog.Top(int(limit), req.ByTotal) {
+		response.Queries = append(response.Queries, &proto.SlowQuery{
+			Statement:    stats.Statement,
+			Count:        stats.Count,
+			TotalMs:      milliseconds(stats.Total),
+			MaxMs:        milliseconds(stats.Max),
+			MeanMs:       milliseconds(stats.Total) / float64(stats.Count),
+			Params:       stats.Last.Params,
+			Plan:         stats.Last.Plan,
+			LastUser:     stats.Last.User,
+			LastDatabase: stats.Last.Database,
+			LastSeen:     stats.Last.Time.Format(time.RFC3339Nano),
+		})
+	}
+	return response, nil
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
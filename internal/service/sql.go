@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/prakhar-5447/GoDB/internal/audit"
+	"github.com/prakhar-5447/GoDB/internal/db"
+	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/query"
+)
+
+// sqlQueryer is satisfied by both *sql.Conn and *sql.Tx.
+type sqlQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ExecuteSQL runs caller-supplied SQL against the caller's database. Admin
+// only. The SQLite authorizer refuses statements that could escape the
+// database file or corrupt it, whatever the caller's role.
+func (s *DatabaseServiceServer) ExecuteSQL(ctx context.Context, req *proto.ExecuteSQLRequest) (*proto.ExecuteSQLResponse, error) {
+	admin, err := requireAdmin(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+
+	statements, err := query.SplitStatements(req.Sql)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(statements) == 0:
+		return nil, fmt.Errorf("sql is required")
+	case !req.Script && len(statements) > 1:
+		return nil, fmt.Errorf("expected exactly one statement, got %d; set script to run several", len(statements))
+	case req.Script && (len(req.Params) > 0 || len(req.NamedParams) > 0):
+		return nil, fmt.Errorf("parameters are not supported in script mode")
+	}
+
+	args := make([]interface{}, 0, len(req.Params)+len(req.NamedParams))
+	for _, v := range req.Params {
+		args = append(args, bindValue(v))
+	}
+	for name, v := range req.NamedParams {
+		if err := query.ValidateIdentifier(name); err != nil {
+			return nil, err
+		}
+		args = append(args, sql.Named(name, bindValue(v)))
+	}
+
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
+	if err != nil {
+		return nil, err
+	}
+	defer database.Close()
+
+	conn, err := db.RestrictedConn(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	audit.LogEvent(fmt.Sprintf("Admin %s executing SQL: %s", admin, req.Sql))
+
+	var response proto.ExecuteSQLResponse
+	if !req.Script {
+		result, err := runStatement(ctx, conn, statements[0], args)
+		if err != nil {
+			return nil, err
+		}
+		response.Results = append(response.Results, result)
+		return &response, nil
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	err = conn.Script(func() error {
+		for i, stmt := range statements {
+			result, err := runStatement(ctx, tx, stmt, nil)
+			if err != nil {
+				return fmt.Errorf("statement %d: %w", i+1, err)
+			}
+			response.Results = append(response.Results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// runStatement executes one statement, returning its rows if it produces any
+// and its change counts otherwise.
+func runStatement(ctx context.Context, q sqlQueryer, stmt string, args []interface{}) (*proto.StatementResult, error) {
+	rows, err := q.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	result := &proto.StatementResult{Sql: stmt}
+
+	if len(cols) == 0 {
+		// Statements without result columns only run when stepped.
+		rows.Next()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		rows.Close()
+		if err := q.QueryRowContext(ctx, "SELECT changes(), last_insert_rowid()").Scan(&result.RowsAffected, &result.LastInsertId); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	for _, col := range cols {
+		result.Columns = append(result.Columns, &proto.ResultColumn{Name: col})
+	}
+	for rows.Next() {
+		if query.MaxRows > 0 && int64(len(result.Rows)) >= query.MaxRows {
+			result.Truncated = true
+			break
+		}
+		values := make([]interface{}, len(cols))
+		valuePtrs := make([]interface{}, len(cols))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, err
+		}
+		row := &proto.TypedRow{Values: make([]*proto.Value, len(values))}
+		for i, v := range values {
+			row.Values[i] = typedValue(v)
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	return result, rows.Err()
+}
+
+// bindValue converts a typed API value into a driver argument.
+func bindValue(v *proto.Value) interface{} {
+	switch v.GetKind() {
+	case proto.Value_INTEGER:
+		return v.IntegerValue
+	case proto.Value_REAL:
+		return v.RealValue
+	case proto.Value_TEXT:
+		return v.TextValue
+	case proto.Value_BLOB:
+		return v.BlobValue
+	}
+	return nil
+}
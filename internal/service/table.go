@@ -2,28 +2,35 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"strings"
 
 	"github.com/prakhar-5447/GoDB/internal/audit"
 	"github.com/prakhar-5447/GoDB/internal/db"
 	"github.com/prakhar-5447/GoDB/internal/pkg/proto"
+	"github.com/prakhar-5447/GoDB/internal/query"
 )
 
 func (s *DatabaseServiceServer) CreateTable(ctx context.Context, req *proto.CreateTableRequest) (*proto.CreateTableResponse, error) {
-	database, err := db.OpenDatabase(req.ConnectionString)
+	createSQL, err := query.CreateTableSQL(req.TableName, req.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
 	if err != nil {
 		return nil, err
 	}
 	defer database.Close()
 
-	var columnDefs []string
-	for colName, colType := range req.Columns {
-		columnDefs = append(columnDefs, fmt.Sprintf("%s %s", colName, colType))
+	conn, err := db.RestrictedConn(ctx, database)
+	if err != nil {
+		return nil, err
 	}
-	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", req.TableName, stringJoin(columnDefs, ", "))
+	defer conn.Close()
 
-	_, err = database.Exec(query)
+	_, err = conn.ExecContext(ctx, createSQL)
 	if err != nil {
 		return nil, err
 	}
@@ -31,24 +38,28 @@ func (s *DatabaseServiceServer) CreateTable(ctx context.Context, req *proto.Crea
 }
 
 func (s *DatabaseServiceServer) InsertRecord(ctx context.Context, req *proto.InsertRecordRequest) (*proto.InsertRecordResponse, error) {
-	database, err := db.OpenDatabase(req.ConnectionString)
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
 	if err != nil {
 		return nil, err
 	}
 	defer database.Close()
 
-	var columns []string
-	var values []string
-	var args []interface{}
+	schema, err := query.LoadSchema(database, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := query.BuildInsert(schema, req.Record)
+	if err != nil {
+		return nil, err
+	}
 
-	for col, val := range req.Record {
-		columns = append(columns, col)
-		values = append(values, "?")
-		args = append(args, val)
+	conn, err := db.RestrictedConn(ctx, database)
+	if err != nil {
+		return nil, err
 	}
+	defer conn.Close()
 
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", req.TableName, stringJoin(columns, ", "), stringJoin(values, ", "))
-	_, err = database.Exec(query, args...)
+	_, err = conn.ExecContext(ctx, stmt.SQL, stmt.Args...)
 	if err != nil {
 		return nil, err
 	}
@@ -57,26 +68,30 @@ func (s *DatabaseServiceServer) InsertRecord(ctx context.Context, req *proto.Ins
 }
 
 func (s *DatabaseServiceServer) InsertMultipleRecords(ctx context.Context, req *proto.InsertMultipleRecordsRequest) (*proto.InsertMultipleRecordsResponse, error) {
-	database, err := db.OpenDatabase(req.ConnectionString)
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
 	if err != nil {
 		return nil, err
 	}
 	defer database.Close()
 
+	schema, err := query.LoadSchema(database, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.RestrictedConn(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
 	// We'll loop over each record in the request and insert them.
 	for _, rec := range req.Records {
-		var columns []string
-		var placeholders []string
-		var args []interface{}
-
-		for col, val := range rec.Data {
-			columns = append(columns, col)
-			placeholders = append(placeholders, "?")
-			args = append(args, val)
+		stmt, err := query.BuildInsert(schema, rec.Data)
+		if err != nil {
+			return nil, err
 		}
-
-		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", req.TableName, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
-		_, err = database.Exec(query, args...)
+		_, err = conn.ExecContext(ctx, stmt.SQL, stmt.Args...)
 		if err != nil {
 			return nil, fmt.Errorf("failed to insert record: %w", err)
 		}
@@ -87,79 +102,69 @@ func (s *DatabaseServiceServer) InsertMultipleRecords(ctx context.Context, req *
 
 func (s *DatabaseServiceServer) QueryData(ctx context.Context, req *proto.QueryDataRequest) (*proto.QueryDataResponse, error) {
 	// Open the database using the connection string.
-	database, err := db.OpenDatabase(req.ConnectionString)
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
 	if err != nil {
 		return nil, err
 	}
 	defer database.Close()
 
-	// Build the query using the provided condition directly.
-	query := fmt.Sprintf("SELECT %s FROM %s", req.Columns, req.TableName)
-	if req.Condition != "" {
-		query += " WHERE " + req.Condition
+	// Build the query from the structured filter or the raw condition.
+	stmt, err := query.BuildSelect(database, req)
+	if err != nil {
+		return nil, err
 	}
 
-	audit.LogEvent(fmt.Sprintf("Executing query: %s", query))
+	audit.LogEvent(fmt.Sprintf("Executing query: %s", stmt.SQL))
 
-	rows, err := database.Query(query)
+	conn, err := db.RestrictedConn(ctx, database)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	defer conn.Close()
 
-	// Get column names.
-	cols, err := rows.Columns()
+	rows, err := conn.QueryContext(ctx, stmt.SQL, stmt.Args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	var response proto.QueryDataResponse
-	var lastID string
-	for rows.Next() {
-		values := make([]interface{}, len(cols))
-		valuePtrs := make([]interface{}, len(cols))
-		for i := range values {
-			valuePtrs[i] = &values[i]
-		}
-
-		if err := rows.Scan(valuePtrs...); err != nil {
-			return nil, err
-		}
-
-		rowData := &proto.QueryRow{
-			Data: make(map[string]string),
-		}
-		for i, col := range cols {
-			val := fmt.Sprintf("%v", values[i])
-			rowData.Data[col] = val
-			// If the column is "id", capture its value as the last ID.
-			if strings.ToLower(col) == "id" {
-				lastID = val
-			}
-		}
-		response.Rows = append(response.Rows, rowData)
-	}
-
-	// Set next_cursor if any rows were returned.
-	if len(response.Rows) > 0 {
-		response.NextCursor = lastID
+	result, _, err := scanQueryRows(rows, stmt.Limit)
+	if err != nil {
+		return nil, err
 	}
+	var response proto.QueryDataResponse
+	response.Rows, response.HasMore, response.NextCursor = pageRows(result, stmt.Limit)
+	rows.Close()
+	recordPlan(database, req.ConnectionString, stmt)
+	recordFilterAccess(database, req.ConnectionString, req.TableName, req.Filter, req.Condition, req.OrderBy...)
 
 	return &response, nil
 }
 
 // UpdateTable updates the structure of an existing table.
 func (s *DatabaseServiceServer) UpdateTable(ctx context.Context, req *proto.UpdateTableRequest) (*proto.UpdateTableResponse, error) {
-	database, err := db.OpenDatabase(req.ConnectionString)
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
 	if err != nil {
 		return nil, err
 	}
 	defer database.Close()
 
-	// Construct ALTER TABLE query
-	query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", req.TableName, req.ColumnName, req.ColumnType)
+	schema, err := query.LoadSchema(database, req.TableName)
+	if err != nil {
+		return nil, err
+	}
+	alterSQL, err := query.AddColumnSQL(schema, req.ColumnName, req.ColumnType)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.RestrictedConn(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
 
-	_, err = database.Exec(query)
+	_, err = conn.ExecContext(ctx, alterSQL)
 	if err != nil {
 		return nil, err
 	}
@@ -169,44 +174,91 @@ func (s *DatabaseServiceServer) UpdateTable(ctx context.Context, req *proto.Upda
 
 // UpdateRecord updates an existing record in the database.
 func (s *DatabaseServiceServer) UpdateRecord(ctx context.Context, req *proto.UpdateRecordRequest) (*proto.UpdateRecordResponse, error) {
-	database, err := db.OpenDatabase(req.ConnectionString)
+	database, err := db.OpenDatabase(ctx, req.ConnectionString)
 	if err != nil {
 		return nil, err
 	}
 	defer database.Close()
 
-	// Construct the UPDATE query dynamically
-	setClauses := []string{}
-	args := []interface{}{}
-
-	for col, val := range req.Updates {
-		setClauses = append(setClauses, fmt.Sprintf("%s = ?", col))
-		args = append(args, val)
+	update, err := query.BuildUpdate(database, req)
+	if err != nil {
+		return nil, err
 	}
 
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", req.TableName, strings.Join(setClauses, ", "), req.Condition)
+	conn, err := db.RestrictedConn(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
 
-	stmt, err := database.Prepare(query)
+	stmt, err := conn.PrepareContext(ctx, update.SQL)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(args...)
+	_, err = stmt.ExecContext(ctx, update.Args...)
 	if err != nil {
 		return nil, err
 	}
+	recordPlan(database, req.ConnectionString, update)
+	recordFilterAccess(database, req.ConnectionString, req.TableName, nil, req.Condition)
 
 	return &proto.UpdateRecordResponse{Message: "Record updated successfully"}, nil
 }
 
-func stringJoin(elements []string, separator string) string {
-	result := ""
-	for i, elem := range elements {
-		if i > 0 {
-			result += separator
+// pageRows trims the extra row a limited SELECT fetches to detect further
+// rows, and returns the "id" of the last row as the cursor for the next page.
+func pageRows(rows []*proto.QueryRow, limit int64) (page []*proto.QueryRow, hasMore bool, cursor string) {
+	page = rows
+	if limit > 0 && int64(len(rows)) > limit {
+		page, hasMore = rows[:limit], true
+	}
+	if len(page) > 0 {
+		for col, val := range page[len(page)-1].Data {
+			if strings.ToLower(col) == "id" {
+				cursor = val
+			}
+		}
+	}
+	return page, hasMore, cursor
+}
+
+// scanQueryRows reads the remaining rows into string maps keyed by column
+// name. It stops one row past limit, or past query.MaxRows when that is
+// lower, so that a statement whose own LIMIT was defeated still cannot
+// return more rows than allowed; the extra row tells pageRows there are more.
+func scanQueryRows(rows *sql.Rows, limit int64) ([]*proto.QueryRow, []string, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	if query.MaxRows > 0 && (limit == 0 || limit > query.MaxRows) {
+		limit = query.MaxRows
+	}
+
+	var result []*proto.QueryRow
+	for rows.Next() {
+		if limit > 0 && int64(len(result)) > limit {
+			break
+		}
+		values := make([]interface{}, len(cols))
+		valuePtrs := make([]interface{}, len(cols))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, nil, err
+		}
+
+		rowData := &proto.QueryRow{
+			Data: make(map[string]string),
+		}
+		for i, col := range cols {
+			rowData.Data[col] = fmt.Sprintf("%v", values[i])
 		}
-		result += elem
+		result = append(result, rowData)
 	}
-	return result
+	return result, cols, rows.Err()
 }
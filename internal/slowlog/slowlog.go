@@ -0,0 +1,235 @@
+// Package slowlog records SQL statements that run longer than a threshold
+// and keeps per-statement statistics for the slowest ones. Statements are
+// normalized, with literals replaced by "?", and only the types of bound
+// parameters are kept, so the log never holds user data.
+package slowlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Thresholds maps a statement's operation (its first keyword, e.g. "SELECT")
+// to the duration above which it is logged. The "" entry applies to other
+// operations; a missing or zero threshold disables logging for them.
+var Thresholds = map[string]time.Duration{"": 500 * time.Millisecond}
+
+// maxStatements bounds the number of distinct statements tracked.
+const maxStatements = 1000
+
+// Entry is one slow statement, written to the log as a JSON line.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	User       string    `json:"user,omitempty"`
+	Database   string    `json:"database,omitempty"`
+	Statement  string    `json:"statement"`
+	Params     []string  `json:"params,omitempty"`
+	DurationMS float64   `json:"duration_ms"`
+	Plan       []string  `json:"plan,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Stats aggregates the slow executions of one normalized statement.
+type Stats struct {
+	Statement string
+	Count     int64
+	Total     time.Duration
+	Max       time.Duration
+	// Last is the most recent slow execution.
+	Last Entry
+}
+
+var (
+	mu    sync.Mutex
+	out   *os.File
+	stats = map[string]*Stats{}
+)
+
+// Threshold returns the slow threshold for a statement's operation.
+func Threshold(operation string) time.Duration {
+	if d, ok := Thresholds[strings.ToUpper(operation)]; ok {
+		return d
+	}
+	return Thresholds[""]
+}
+
+// ParseThresholds reads a threshold setting such as "500ms" or
+// "500ms,select=1s,insert=100ms"; an entry without an operation is the
+// default.
+func ParseThresholds(value string) (map[string]time.Duration, error) {
+	thresholds := map[string]time.Duration{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		operation, duration, ok := strings.Cut(part, "=")
+		if !ok {
+			operation, duration = "", part
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(duration))
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("invalid slow query threshold %q", part)
+		}
+		thresholds[strings.ToUpper(strings.TrimSpace(operation))] = d
+	}
+	return thresholds, nil
+}
+
+// Open starts writing slow statements to path and replaces the statistics
+// with those of the entries already there.
+func Open(path string) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	stats = map[string]*Stats{}
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		var e Entry
+		if len(line) > 0 && json.Unmarshal(line, &e) == nil {
+			add(e)
+		}
+		if err != nil {
+			break
+		}
+	}
+	out = file
+	return nil
+}
+
+// Close stops writing the log.
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if out == nil {
+		return nil
+	}
+	err := out.Close()
+	out = nil
+	return err
+}
+
+// Record logs a slow statement. Statement must already be normalized.
+func Record(e Entry) {
+	mu.Lock()
+	defer mu.Unlock()
+	add(e)
+	if out == nil {
+		return
+	}
+	var line bytes.Buffer
+	encoder := json.NewEncoder(&line)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(e); err != nil {
+		return
+	}
+	if _, err := out.Write(line.Bytes()); err != nil {
+		log.Printf("Slow query log: %v", err)
+	}
+}
+
+func add(e Entry) {
+	s, ok := stats[e.Statement]
+	if !ok {
+		if len(stats) >= maxStatements {
+			evictFastest()
+		}
+		s = &Stats{Statement: e.Statement}
+		stats[e.Statement] = s
+	}
+	d := time.Duration(e.DurationMS * float64(time.Millisecond))
+	s.Count++
+	s.Total += d
+	if d > s.Max {
+		s.Max = d
+	}
+	s.Last = e
+}
+
+// evictFastest forgets the statement with the lowest maximum duration.
+func evictFastest() {
+	var victim *Stats
+	for _, s := range stats {
+		if victim == nil || s.Max < victim.Max {
+			victim = s
+		}
+	}
+	if victim != nil {
+		delete(stats, victim.Statement)
+	}
+}
+
+// Top returns up to n statements ordered by their slowest execution, or by
+// total time spent when byTotal is set.
+func Top(n int, byTotal bool) []Stats {
+	mu.Lock()
+	list := make([]Stats, 0, len(stats))
+	for _, s := range stats {
+		list = append(list, *s)
+	}
+	mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].Max, list[j].Max
+		if byTotal {
+			a, b = list[i].Total, list[j].Total
+		}
+		if a != b {
+			return a > b
+		}
+		return list[i].Statement < list[j].Statement
+	})
+	if n > 0 && len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+var (
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	blobLiteral   = regexp.MustCompile(`(?i)\bx'[0-9a-f]*'`)
+	numberLiteral = regexp.MustCompile(`\b\d+(?:\.\d+)?(?:e[+-]?\d+)?\b`)
+	numberedParam = regexp.MustCompile(`\?\d+`)
+	placeholders  = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+	whitespace    = regexp.MustCompile(`\s+`)
+)
+
+// Normalize replaces the literals of a statement with "?", folds IN lists
+// and VALUES rows of placeholders into "(?...)" and collapses whitespace, so
+// that statements differing only in their values compare equal.
+func Normalize(statement string) string {
+	s := blobLiteral.ReplaceAllString(statement, "?")
+	s = stringLiteral.ReplaceAllString(s, "?")
+	s = numberedParam.ReplaceAllString(s, "?")
+	s = numberLiteral.ReplaceAllString(s, "?")
+	s = placeholders.ReplaceAllString(s, "(?...)")
+	return strings.TrimSpace(whitespace.ReplaceAllString(s, " "))
+}
+
+// ParamType names the SQLite storage class of a bound parameter value.
+func ParamType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "NULL"
+	case int64, int, int32, bool:
+		return "INTEGER"
+	case float64, float32:
+		return "REAL"
+	case []byte:
+		return "BLOB"
+	}
+	return "TEXT"
+}
@@ -0,0 +1,101 @@
+// Package stats keeps in-memory counters about the queries the server runs.
+// Counters are per user database and reset when the server restarts.
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prakhar-5447/GoDB/internal/query"
+)
+
+// IndexHit counts how often a plan used an index.
+type IndexHit struct {
+	Table    string
+	Index    string
+	Hits     int64
+	LastUsed time.Time
+}
+
+// TableScan counts full table scans of a table.
+type TableScan struct {
+	Table     string
+	FullScans int64
+	LastScan  time.Time
+}
+
+// Usage is a snapshot of the recorded plans for one database.
+type Usage struct {
+	Queries int64
+	Indexes []IndexHit
+	Scans   []TableScan
+}
+
+type databaseUsage struct {
+	queries int64
+	indexes map[string]*IndexHit
+	scans   map[string]*TableScan
+}
+
+var (
+	usageMu sync.Mutex
+	usage   = map[string]*databaseUsage{}
+)
+
+// RecordPlan adds the table accesses of an executed statement's plan to the
+// counters of a database. The key identifies the database, e.g. "user/db".
+func RecordPlan(key string, plan *query.Plan) {
+	now := time.Now()
+
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	u, ok := usage[key]
+	if !ok {
+		u = &databaseUsage{indexes: map[string]*IndexHit{}, scans: map[string]*TableScan{}}
+		usage[key] = u
+	}
+	u.queries++
+	for _, step := range plan.Steps {
+		switch {
+		case step.Index != "":
+			hit, ok := u.indexes[step.Index]
+			if !ok {
+				hit = &IndexHit{Table: step.Table, Index: step.Index}
+				u.indexes[step.Index] = hit
+			}
+			hit.Hits++
+			hit.LastUsed = now
+		case step.FullScan():
+			scan, ok := u.scans[step.Table]
+			if !ok {
+				scan = &TableScan{Table: step.Table}
+				u.scans[step.Table] = scan
+			}
+			scan.FullScans++
+			scan.LastScan = now
+		}
+	}
+}
+
+// IndexUsage returns a snapshot of the counters for a database, sorted by name.
+func IndexUsage(key string) Usage {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+
+	u, ok := usage[key]
+	if !ok {
+		return Usage{}
+	}
+	snapshot := Usage{Queries: u.queries}
+	for _, hit := range u.indexes {
+		snapshot.Indexes = append(snapshot.Indexes, *hit)
+	}
+	for _, scan := range u.scans {
+		snapshot.Scans = append(snapshot.Scans, *scan)
+	}
+	sort.Slice(snapshot.Indexes, func(i, j int) bool { return snapshot.Indexes[i].Index < snapshot.Indexes[j].Index })
+	sort.Slice(snapshot.Scans, func(i, j int) bool { return snapshot.Scans[i].Table < snapshot.Scans[j].Table })
+	return snapshot
+}
@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxPatternsPerDatabase bounds the number of distinct access patterns kept
+// for one database; the least recently seen pattern is evicted first.
+const maxPatternsPerDatabase = 256
+
+// AccessPattern describes how a statement located rows in a table: the
+// columns compared for equality, the columns used in range predicates and
+// the sort keys.
+type AccessPattern struct {
+	Table    string
+	Equality []string
+	Range    []string
+	Sort     []string
+	Count    int64
+	LastSeen time.Time
+}
+
+func (p AccessPattern) signature() string {
+	return strings.ToLower(p.Table + "|" + strings.Join(p.Equality, ",") + "|" + strings.Join(p.Range, ",") + "|" + strings.Join(p.Sort, ","))
+}
+
+var (
+	workloadMu sync.Mutex
+	workload   = map[string]map[string]*AccessPattern{}
+)
+
+// RecordAccess counts one occurrence of an access pattern for a database.
+// Patterns without any filter or sort columns are ignored.
+func RecordAccess(key string, p AccessPattern) {
+	if len(p.Equality) == 0 && len(p.Range) == 0 && len(p.Sort) == 0 {
+		return
+	}
+
+	workloadMu.Lock()
+	defer workloadMu.Unlock()
+
+	patterns, ok := workload[key]
+	if !ok {
+		patterns = map[string]*AccessPattern{}
+		workload[key] = patterns
+	}
+	sig := p.signature()
+	existing, ok := patterns[sig]
+	if !ok {
+		if len(patterns) >= maxPatternsPerDatabase {
+			evictOldestPattern(patterns)
+		}
+		copied := p
+		copied.Count = 0
+		existing = &copied
+		patterns[sig] = existing
+	}
+	existing.Count++
+	existing.LastSeen = time.Now()
+}
+
+func evictOldestPattern(patterns map[string]*AccessPattern) {
+	var oldestSig string
+	var oldest time.Time
+	for sig, p := range patterns {
+		if oldestSig == "" || p.LastSeen.Before(oldest) {
+			oldestSig, oldest = sig, p.LastSeen
+		}
+	}
+	delete(patterns, oldestSig)
+}
+
+// Workload returns the recorded access patterns of a database, most frequent first.
+func Workload(key string) []AccessPattern {
+	workloadMu.Lock()
+	defer workloadMu.Unlock()
+
+	var patterns []AccessPattern
+	for _, p := range workload[key] {
+		patterns = append(patterns, *p)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].Count != patterns[j].Count {
+			return patterns[i].Count > patterns[j].Count
+		}
+		return patterns[i].signature() < patterns[j].signature()
+	})
+	return patterns
+}
+
+// WorkloadKeys returns the keys of all databases with recorded access patterns.
+func WorkloadKeys() []string {
+	workloadMu.Lock()
+	defer workloadMu.Unlock()
+
+	keys := make([]string, 0, len(workload))
+	for key := range workload {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,269 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Exporter receives finished spans.
+type Exporter interface {
+	Export(span SpanData)
+	// Shutdown flushes buffered spans and releases the exporter.
+	Shutdown(ctx context.Context) error
+}
+
+var exporter atomic.Pointer[Exporter]
+
+// SetExporter installs the exporter spans are sent to. Tracing is disabled
+// until one is set.
+func SetExporter(e Exporter) {
+	if e == nil {
+		exporter.Store(nil)
+		return
+	}
+	exporter.Store(&e)
+}
+
+func currentExporter() Exporter {
+	if e := exporter.Load(); e != nil {
+		return *e
+	}
+	return nil
+}
+
+// Shutdown flushes and removes the installed exporter.
+func Shutdown(ctx context.Context) error {
+	e := currentExporter()
+	SetExporter(nil)
+	if e == nil {
+		return nil
+	}
+	return e.Shutdown(ctx)
+}
+
+// ServiceName is reported as the service.name resource attribute.
+var ServiceName = "godb-server"
+
+// otlpSpan is a span in the OTLP/JSON encoding.
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+// otlpStatus codes: 1 is OK, 2 is ERROR.
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+func toOTLP(s SpanData) otlpSpan {
+	span := otlpSpan{
+		TraceID:           s.Context.TraceID.String(),
+		SpanID:            s.Context.SpanID.String(),
+		Name:              s.Name,
+		Kind:              s.Kind,
+		StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+		Status:            otlpStatus{Code: 1},
+	}
+	if s.Parent.IsValid() {
+		span.ParentSpanID = s.Parent.String()
+	}
+	keys := make([]string, 0, len(s.Attributes))
+	for k := range s.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		span.Attributes = append(span.Attributes, otlpAttribute{Key: k, Value: otlpValue{StringValue: s.Attributes[k]}})
+	}
+	if s.Error != "" {
+		span.Status = otlpStatus{Code: 2, Message: s.Error}
+	}
+	return span
+}
+
+// FileExporter appends spans to a file, one OTLP/JSON span per line.
+type FileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileExporter opens (or creates) path for appending spans.
+func NewFileExporter(path string) (*FileExporter, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileExporter{file: file}, nil
+}
+
+func (f *FileExporter) Export(span SpanData) {
+	line, err := json.Marshal(toOTLP(span))
+	if err != nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return
+	}
+	if _, err := f.file.Write(append(line, '\n')); err != nil {
+		log.Printf("Tracing: failed to write span: %v", err)
+	}
+}
+
+func (f *FileExporter) Shutdown(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// OTLP exporter batching.
+const (
+	otlpQueueSize     = 4096
+	otlpBatchSize     = 512
+	otlpFlushInterval = 5 * time.Second
+)
+
+// OTLPExporter sends spans in batches to an OTLP/HTTP collector using the
+// JSON encoding. Spans are dropped rather than blocking RPCs when the
+// collector falls behind.
+type OTLPExporter struct {
+	endpoint string
+	client   *http.Client
+	queue    chan SpanData
+	done     chan struct{}
+	dropped  atomic.Int64
+	closing  sync.Once
+}
+
+// NewOTLPExporter starts an exporter posting to endpoint, e.g.
+// http://localhost:4318/v1/traces.
+func NewOTLPExporter(endpoint string) *OTLPExporter {
+	e := &OTLPExporter{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		queue:    make(chan SpanData, otlpQueueSize),
+		done:     make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+func (e *OTLPExporter) Export(span SpanData) {
+	defer func() {
+		// The queue is closed by Shutdown; late spans are dropped.
+		if recover() != nil {
+			e.dropped.Add(1)
+		}
+	}()
+	select {
+	case e.queue <- span:
+	default:
+		e.dropped.Add(1)
+	}
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+	var batch []SpanData
+	for {
+		select {
+		case span, ok := <-e.queue:
+			if !ok {
+				e.send(batch)
+				return
+			}
+			if batch = append(batch, span); len(batch) >= otlpBatchSize {
+				e.send(batch)
+				batch = nil
+			}
+		case <-ticker.C:
+			e.send(batch)
+			batch = nil
+		}
+	}
+}
+
+func (e *OTLPExporter) send(batch []SpanData) {
+	if n := e.dropped.Swap(0); n > 0 {
+		log.Printf("Tracing: dropped %d spans", n)
+	}
+	if len(batch) == 0 {
+		return
+	}
+	spans := make([]otlpSpan, len(batch))
+	for i, s := range batch {
+		spans[i] = toOTLP(s)
+	}
+	payload := map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": []otlpAttribute{{Key: "service.name", Value: otlpValue{StringValue: ServiceName}}},
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]string{"name": "github.com/prakhar-5447/GoDB"},
+				"spans": spans,
+			}},
+		}},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	resp, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err == nil {
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			err = fmt.Errorf("collector returned %s", resp.Status)
+		}
+	}
+	if err != nil {
+		log.Printf("Tracing: failed to export %d spans: %v", len(batch), err)
+	}
+}
+
+// Shutdown sends the queued spans and stops the exporter.
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.closing.Do(func() { close(e.queue) })
+	select {
+	case <-e.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	"context"
+	"path"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// traceParentHeader is the W3C Trace Context header, carried as gRPC metadata.
+const traceParentHeader = "traceparent"
+
+// UnaryServerInterceptor starts a server span for every unary RPC, joining
+// the caller's trace when the request carries a valid traceparent, and
+// returns the span's traceparent in the response headers.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if currentExporter() == nil {
+		return handler(ctx, req)
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(traceParentHeader); len(values) > 0 {
+			// An invalid header starts a new trace, as the specification asks.
+			if parent, err := ParseTraceParent(values[0]); err == nil {
+				ctx = ContextWithRemoteParent(ctx, parent)
+			}
+		}
+	}
+
+	ctx, span := Start(ctx, info.FullMethod, KindServer)
+	defer span.End()
+	span.SetAttribute("rpc.system", "grpc")
+	span.SetAttribute("rpc.service", path.Dir(info.FullMethod)[1:])
+	span.SetAttribute("rpc.method", path.Base(info.FullMethod))
+	grpc.SetHeader(ctx, metadata.Pairs(traceParentHeader, span.Context().TraceParent()))
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		span.SetAttribute("rpc.grpc.status_code", status.Code(err).String())
+		span.SetError(err)
+	}
+	return resp, err
+}
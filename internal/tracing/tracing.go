@@ -0,0 +1,224 @@
+// Package tracing records spans for RPCs, authentication, database opens and
+// SQL statements, propagates W3C trace context and exports finished spans
+// to a local file or an OTLP/HTTP collector. It follows OpenTelemetry's
+// model closely enough for its collectors to ingest the output, without
+// depending on the OpenTelemetry SDK.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceID and SpanID identify traces and spans as in W3C Trace Context.
+type (
+	TraceID [16]byte
+	SpanID  [8]byte
+)
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+func (s SpanID) String() string  { return hex.EncodeToString(s[:]) }
+
+// IsValid reports whether the ID is not all zeros.
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+// IsValid reports whether the ID is not all zeros.
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext is the part of a span that crosses process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether both IDs are set.
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// TraceParent renders sc as a W3C traceparent header value.
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceParent parses a W3C traceparent header value. Versions other
+// than 00 are read by their first four fields, as the specification asks.
+func ParseTraceParent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, fmt.Errorf("malformed traceparent %q", value)
+	}
+	var sc SpanContext
+	version, err1 := hex.DecodeString(parts[0])
+	trace, err2 := hex.DecodeString(parts[1])
+	span, err3 := hex.DecodeString(parts[2])
+	flags, err4 := hex.DecodeString(parts[3])
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil ||
+		len(version) != 1 || len(trace) != len(sc.TraceID) || len(span) != len(sc.SpanID) || len(flags) != 1 ||
+		strings.ToLower(value) != value {
+		return SpanContext{}, fmt.Errorf("malformed traceparent %q", value)
+	}
+	copy(sc.TraceID[:], trace)
+	copy(sc.SpanID[:], span)
+	sc.Sampled = flags[0]&1 == 1
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("traceparent %q has a zero trace or span ID", value)
+	}
+	return sc, nil
+}
+
+// Span kinds, numbered as in OTLP.
+const (
+	KindInternal = 1
+	KindServer   = 2
+	KindClient   = 3
+)
+
+// Span is an operation being timed. A nil *Span is valid and records
+// nothing, which is what Start returns while tracing is disabled.
+type Span struct {
+	mu         sync.Mutex
+	name       string
+	kind       int
+	context    SpanContext
+	parent     SpanID
+	start, end time.Time
+	attributes map[string]string
+	err        string
+	ended      bool
+}
+
+// SpanData is the immutable record of a finished span handed to exporters.
+type SpanData struct {
+	Name       string
+	Kind       int
+	Context    SpanContext
+	Parent     SpanID
+	Start, End time.Time
+	Attributes map[string]string
+	// Error is the error message of a failed operation; empty on success.
+	Error string
+}
+
+type spanKey struct{}
+
+// SpanFromContext returns the span stored in ctx, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+type remoteKey struct{}
+
+// ContextWithRemoteParent records a span context received from a caller, so
+// that the next span started from ctx joins the caller's trace.
+func ContextWithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+// ParentFromContext returns the span context new spans started from ctx
+// would be children of.
+func ParentFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.context
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// Start begins a span named name as a child of the span or remote parent in
+// ctx, or as the root of a new trace. It returns ctx unchanged and a nil
+// span when no exporter is configured.
+func Start(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	span := StartWithParent(ParentFromContext(ctx), name, kind)
+	if span == nil {
+		return ctx, nil
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// StartWithParent begins a span with an explicit parent, which may be the
+// zero SpanContext for a new trace.
+func StartWithParent(parent SpanContext, name string, kind int) *Span {
+	if currentExporter() == nil {
+		return nil
+	}
+	span := &Span{name: name, kind: kind, start: time.Now(), attributes: map[string]string{}}
+	if parent.IsValid() {
+		span.context.TraceID = parent.TraceID
+		span.parent = parent.SpanID
+	} else {
+		rand.Read(span.context.TraceID[:])
+	}
+	rand.Read(span.context.SpanID[:])
+	span.context.Sampled = true
+	return span
+}
+
+// Context returns the span's context, or the zero SpanContext for nil.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// SetAttribute attaches a key/value pair to the span.
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes[key] = value
+}
+
+// SetError marks the span as failed. A nil error is ignored.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err.Error()
+}
+
+// End finishes the span and hands it to the exporter. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	data := SpanData{
+		Name:       s.name,
+		Kind:       s.kind,
+		Context:    s.context,
+		Parent:     s.parent,
+		Start:      s.start,
+		End:        s.end,
+		Attributes: make(map[string]string, len(s.attributes)),
+		Error:      s.err,
+	}
+	for k, v := range s.attributes {
+		data.Attributes[k] = v
+	}
+	s.mu.Unlock()
+
+	if exporter := currentExporter(); exporter != nil {
+		exporter.Export(data)
+	}
+}